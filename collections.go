@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// dict builds a map from key-value pairs.
+// Usage: {{ $labels := dict "app" .app "tier" "web" }}.
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: expected even number of arguments, got %d", len(pairs))
+	}
+	result := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key must be a string, got %T", pairs[i])
+		}
+		result[key] = pairs[i+1]
+	}
+	return result, nil
+}
+
+// list builds a list from arguments.
+func list(items ...interface{}) []interface{} {
+	return items
+}
+
+// appendList returns a new list with items added to the end.
+// Usage: {{ $regions := append .regions "eu-west-1" }}.
+func appendList(in interface{}, items ...interface{}) ([]interface{}, error) {
+	l, err := toList(in)
+	if err != nil {
+		return nil, fmt.Errorf("append: %w", err)
+	}
+	result := make([]interface{}, 0, len(l)+len(items))
+	result = append(result, l...)
+	return append(result, items...), nil
+}
+
+// keys returns sorted keys of a map.
+func keys(in interface{}) ([]string, error) {
+	m, err := toMap(in)
+	if err != nil {
+		return nil, fmt.Errorf("keys: %w", err)
+	}
+	return sortedKeys(m), nil
+}
+
+// values returns values of a map ordered by key.
+func values(in interface{}) ([]interface{}, error) {
+	m, err := toMap(in)
+	if err != nil {
+		return nil, fmt.Errorf("values: %w", err)
+	}
+	result := make([]interface{}, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		result = append(result, m[k])
+	}
+	return result, nil
+}
+
+// sortAlpha sorts list as strings.
+func sortAlpha(in interface{}) ([]string, error) {
+	l, err := toStrings(in)
+	if err != nil {
+		return nil, fmt.Errorf("sortAlpha: %w", err)
+	}
+	result := append([]string(nil), l...)
+	sort.Strings(result)
+	return result, nil
+}
+
+// sortBy sorts list of maps by the given field, numerically when both values are numbers.
+// Usage: {{ range .services | sortBy "name" }}.
+func sortBy(field string, in interface{}) ([]interface{}, error) {
+	l, err := toList(in)
+	if err != nil {
+		return nil, fmt.Errorf("sortBy: %w", err)
+	}
+	result := append([]interface{}(nil), l...)
+	sort.SliceStable(result, func(i, j int) bool {
+		return lessValues(fieldValue(result[i], field), fieldValue(result[j], field))
+	})
+	return result, nil
+}
+
+// uniq returns list without duplicates, keeping the first occurrence.
+func uniq(in interface{}) ([]interface{}, error) {
+	l, err := toList(in)
+	if err != nil {
+		return nil, fmt.Errorf("uniq: %w", err)
+	}
+	result := make([]interface{}, 0, len(l))
+	for _, item := range l {
+		found := false
+		for _, existing := range result {
+			if equalValues(item, existing) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// first returns the first element of a list, or nil if it is empty.
+func first(in interface{}) (interface{}, error) {
+	l, err := toList(in)
+	if err != nil {
+		return nil, fmt.Errorf("first: %w", err)
+	}
+	if len(l) == 0 {
+		return nil, nil
+	}
+	return l[0], nil
+}
+
+// last returns the last element of a list, or nil if it is empty.
+func last(in interface{}) (interface{}, error) {
+	l, err := toList(in)
+	if err != nil {
+		return nil, fmt.Errorf("last: %w", err)
+	}
+	if len(l) == 0 {
+		return nil, nil
+	}
+	return l[len(l)-1], nil
+}
+
+// pluck returns the given field of every map in list.
+// Usage: {{ .services | pluck "name" | toJSON }}.
+func pluck(field string, in interface{}) ([]interface{}, error) {
+	l, err := toList(in)
+	if err != nil {
+		return nil, fmt.Errorf("pluck: %w", err)
+	}
+	result := make([]interface{}, 0, len(l))
+	for _, item := range l {
+		result = append(result, fieldValue(item, field))
+	}
+	return result, nil
+}
+
+// groupBy groups list of maps by the given field.
+// Usage: {{ range $team, $services := .services | groupBy "team" }}.
+func groupBy(field string, in interface{}) (map[string]interface{}, error) {
+	l, err := toList(in)
+	if err != nil {
+		return nil, fmt.Errorf("groupBy: %w", err)
+	}
+	result := map[string]interface{}{}
+	for _, item := range l {
+		key := fmt.Sprint(fieldValue(item, field))
+		group, _ := result[key].([]interface{})
+		result[key] = append(group, item)
+	}
+	return result, nil
+}
+
+// where returns maps from list whose field equals value.
+// Usage: {{ range .services | where "enabled" true }}.
+func where(field string, value interface{}, in interface{}) ([]interface{}, error) {
+	l, err := toList(in)
+	if err != nil {
+		return nil, fmt.Errorf("where: %w", err)
+	}
+	result := []interface{}{}
+	for _, item := range l {
+		if equalValues(fieldValue(item, field), value) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// merge deeply merges maps into a new map; values from earlier maps take precedence,
+// the same way `vars` take precedence over `vars_path`.
+// Usage: {{ $config := merge .overrides .defaults }}.
+func merge(maps ...interface{}) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	for _, in := range maps {
+		m, err := toMap(in)
+		if err != nil {
+			return nil, fmt.Errorf("merge: %w", err)
+		}
+		mergeMaps(result, m)
+	}
+	return result, nil
+}
+
+// mergeMaps copies values from src missing in dst, recursing into nested maps.
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		existing, ok := dst[k]
+		if !ok {
+			if nested, err := toMap(v); err == nil {
+				copied := map[string]interface{}{}
+				mergeMaps(copied, nested)
+				v = copied
+			}
+			dst[k] = v
+			continue
+		}
+		dstNested, dstErr := toMap(existing)
+		srcNested, srcErr := toMap(v)
+		if dstErr == nil && srcErr == nil {
+			copied := map[string]interface{}{}
+			mergeMaps(copied, dstNested)
+			mergeMaps(copied, srcNested)
+			dst[k] = copied
+		}
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// fieldValue returns value of field if item is a map, nil otherwise.
+func fieldValue(item interface{}, field string) interface{} {
+	m, err := toMap(item)
+	if err != nil {
+		return nil
+	}
+	return m[field]
+}
+
+func equalValues(a, b interface{}) bool {
+	af, aErr := toNumber(a)
+	bf, bErr := toNumber(b)
+	if aErr == nil && bErr == nil {
+		return af == bf
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func lessValues(a, b interface{}) bool {
+	af, aErr := toNumber(a)
+	bf, bErr := toNumber(b)
+	if aErr == nil && bErr == nil {
+		return af < bf
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+// toNumber is like toFloat, but does not accept strings,
+// so "10" and 10 are not considered equal.
+func toNumber(in interface{}) (float64, error) {
+	if _, ok := in.(string); ok {
+		return 0, fmt.Errorf("unsupported type %T", in)
+	}
+	return toFloat(in)
+}
+
+// toList converts any slice or array (e.g. []interface{} decoded from YAML) to []interface{}.
+func toList(in interface{}) ([]interface{}, error) {
+	if l, ok := in.([]interface{}); ok {
+		return l, nil
+	}
+	if in == nil {
+		return nil, nil
+	}
+	v := reflect.ValueOf(in)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a list, got %T", in)
+	}
+	result := make([]interface{}, v.Len())
+	for i := range result {
+		result[i] = v.Index(i).Interface()
+	}
+	return result, nil
+}
+
+// toStrings converts any list to []string, formatting non-string elements.
+func toStrings(in interface{}) ([]string, error) {
+	if l, ok := in.([]string); ok {
+		return l, nil
+	}
+	l, err := toList(in)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(l))
+	for _, item := range l {
+		result = append(result, fmt.Sprint(item))
+	}
+	return result, nil
+}
+
+// toMap converts map with string keys (e.g. vars or map[string]interface{} decoded from YAML)
+// to map[string]interface{}.
+func toMap(in interface{}) (map[string]interface{}, error) {
+	switch m := in.(type) {
+	case map[string]interface{}:
+		return m, nil
+	case vars:
+		return m, nil
+	}
+	if in == nil {
+		return nil, fmt.Errorf("expected a map, got nil")
+	}
+	v := reflect.ValueOf(in)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("expected a map, got %T", in)
+	}
+	result := make(map[string]interface{}, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		result[iter.Key().String()] = iter.Value().Interface()
+	}
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+var services = []interface{}{
+	map[string]interface{}{"name": "web", "team": "core", "replicas": 3, "enabled": true},
+	map[string]interface{}{"name": "api", "team": "core", "replicas": 10, "enabled": false},
+	map[string]interface{}{"name": "worker", "team": "data", "replicas": 2, "enabled": true},
+}
+
+func TestDict(t *testing.T) {
+	actual, err := dict("app", "nginx", "replicas", 3)
+	if err != nil {
+		t.Fatalf("dict returned an error: %v", err)
+	}
+	expected := map[string]interface{}{"app": "nginx", "replicas": 3}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("dict was incorrect, got: %v, want: %v.", actual, expected)
+	}
+
+	if _, err := dict("app"); err == nil {
+		t.Errorf("dict with odd number of arguments succeeded, but was expected to fail")
+	}
+	if _, err := dict(1, "one"); err == nil {
+		t.Errorf("dict with non-string key succeeded, but was expected to fail")
+	}
+}
+
+func TestListFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func() (interface{}, error)
+		expected interface{}
+	}{
+		{"append", func() (interface{}, error) { return appendList([]string{"a"}, "b", "c") }, []interface{}{"a", "b", "c"}},
+		{"keys", func() (interface{}, error) { return keys(vars{"b": 1, "a": 2}) }, []string{"a", "b"}},
+		{"values", func() (interface{}, error) { return values(map[string]interface{}{"b": 1, "a": 2}) }, []interface{}{2, 1}},
+		{"sortAlpha", func() (interface{}, error) { return sortAlpha([]interface{}{"b", "c", "a"}) }, []string{"a", "b", "c"}},
+		{"uniq", func() (interface{}, error) { return uniq([]interface{}{1, "1", 1, 1.0, "a"}) }, []interface{}{1, "1", "a"}},
+		{"first", func() (interface{}, error) { return first([]interface{}{"a", "b"}) }, "a"},
+		{"first", func() (interface{}, error) { return first([]interface{}{}) }, nil},
+		{"last", func() (interface{}, error) { return last([]string{"a", "b"}) }, "b"},
+		{"pluck", func() (interface{}, error) { return pluck("name", services) }, []interface{}{"web", "api", "worker"}},
+		{
+			"sortBy",
+			func() (interface{}, error) {
+				l, err := sortBy("replicas", services)
+				if err != nil {
+					return nil, err
+				}
+				return pluck("name", l)
+			},
+			[]interface{}{"worker", "web", "api"},
+		},
+		{
+			"where",
+			func() (interface{}, error) {
+				l, err := where("enabled", true, services)
+				if err != nil {
+					return nil, err
+				}
+				return pluck("name", l)
+			},
+			[]interface{}{"web", "worker"},
+		},
+		{
+			"groupBy",
+			func() (interface{}, error) { return groupBy("team", services) },
+			map[string]interface{}{
+				"core": []interface{}{services[0], services[1]},
+				"data": []interface{}{services[2]},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		actual, err := tt.fn()
+		if err != nil {
+			t.Errorf("%s returned an error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%s was incorrect, got: %v, want: %v.", tt.name, actual, tt.expected)
+		}
+	}
+
+	if _, err := first("string"); err == nil {
+		t.Errorf("first with non-list argument succeeded, but was expected to fail")
+	}
+	if _, err := keys([]string{}); err == nil {
+		t.Errorf("keys with non-map argument succeeded, but was expected to fail")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	overrides := map[string]interface{}{
+		"image":     map[string]interface{}{"tag": "v2"},
+		"resources": "small",
+	}
+	defaults := vars{
+		"image":     map[string]interface{}{"repository": "nginx", "tag": "latest"},
+		"resources": map[string]interface{}{"cpu": "100m"},
+		"replicas":  1,
+	}
+
+	actual, err := merge(overrides, defaults)
+	if err != nil {
+		t.Fatalf("merge returned an error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"image":     map[string]interface{}{"repository": "nginx", "tag": "v2"},
+		"resources": "small",
+		"replicas":  1,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("merge was incorrect, got: %v, want: %v.", actual, expected)
+	}
+	if overrides["image"].(map[string]interface{})["repository"] != nil {
+		t.Errorf("merge modified its arguments: %v", overrides)
+	}
+}
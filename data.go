@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// dataFormat returns format of data file by its extension: "toml", "json", "csv" or "yaml".
+func dataFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return "toml"
+	case ".json":
+		return "json"
+	case ".csv":
+		return "csv"
+	default:
+		return "yaml"
+	}
+}
+
+// decodeData parses data in the given format into maps and lists,
+// the same types yaml.Unmarshal produces for vars.
+func decodeData(format string, data []byte) (interface{}, error) {
+	var result interface{}
+	switch format {
+	case "yaml":
+		if err := yaml.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+	case "json":
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		if err := d.Decode(&result); err != nil {
+			return nil, err
+		}
+		return normalizeJSON(result), nil
+	case "toml":
+		m := map[string]interface{}{}
+		if _, err := toml.Decode(string(data), &m); err != nil {
+			return nil, err
+		}
+		return normalizeTOML(m), nil
+	case "csv":
+		return decodeCSV(data)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	return result, nil
+}
+
+// normalizeJSON converts json.Number to int or float64, like YAML decoder does.
+func normalizeJSON(in interface{}) interface{} {
+	switch v := in.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalizeJSON(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeJSON(item)
+		}
+	}
+	return in
+}
+
+// normalizeTOML converts int64 to int and []map[string]interface{} (arrays of tables)
+// to []interface{}, like YAML decoder does.
+func normalizeTOML(in interface{}) interface{} {
+	switch v := in.(type) {
+	case int64:
+		return int(v)
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalizeTOML(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeTOML(item)
+		}
+	case []map[string]interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeTOML(item)
+		}
+		return result
+	}
+	return in
+}
+
+// decodeCSV parses CSV with a header row into a list of maps.
+func decodeCSV(data []byte) ([]interface{}, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []interface{}{}, nil
+	}
+
+	header := records[0]
+	result := make([]interface{}, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+// decodeVars parses vars file, picking decoder by file extension.
+func decodeVars(path string, data []byte) (vars, error) {
+	format := dataFormat(path)
+	if format == "yaml" {
+		// decode straight into a map, so non-string keys are converted to strings;
+		// nested maps are decoded as map[string]interface{} too
+		var m map[string]interface{}
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+
+	result, err := decodeData(format, data)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+	m, err := toMap(result)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDataReader(t *testing.T) {
+	t.Setenv("INPUT_ROOT", "./testdata")
+	r := newFileReader("./testdata/data/template.txt")
+
+	env := map[string]interface{}{
+		"name":     "staging",
+		"replicas": 2,
+		"regions":  []interface{}{"us-east-1", "eu-west-1"},
+	}
+
+	tests := []struct {
+		format   string
+		path     string
+		expected interface{}
+	}{
+		{"yaml", "env.yml", env},
+		{"json", "env.json", env},
+		{"toml", "env.toml", env},
+		{"csv", "services.csv", []interface{}{
+			map[string]interface{}{"name": "web", "port": "80"},
+			map[string]interface{}{"name": "api, v2", "port": "8080"},
+		}},
+	}
+
+	for _, tt := range tests {
+		actual, err := r.dataReader(tt.format)(tt.path)
+		if err != nil {
+			t.Errorf("read %s %q returned an error: %v", tt.format, tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("read %s %q was incorrect, got: %#v, want: %#v.", tt.format, tt.path, actual, tt.expected)
+		}
+	}
+
+	if _, err := r.dataReader("json")("env.toml"); err == nil {
+		t.Errorf("readJSON of TOML file succeeded, but was expected to fail")
+	}
+	if _, err := r.dataReader("yaml")("../../main.go"); err == nil {
+		t.Errorf("readYAML outside of root succeeded, but was expected to fail")
+	}
+}
+
+func TestDecodeVars(t *testing.T) {
+	actual, err := decodeVars("vars.toml", []byte(`image = "nginx"`))
+	if err != nil {
+		t.Fatalf("decodeVars returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(actual, vars{"image": "nginx"}) {
+		t.Errorf("decodeVars was incorrect, got: %v", actual)
+	}
+
+	actual, err = decodeVars("vars.yml", []byte("ports:\n  80: http\n1: one\ntrue: yes\n"))
+	if err != nil {
+		t.Fatalf("decodeVars returned an error: %v", err)
+	}
+	expected := vars{"ports": map[interface{}]interface{}{80: "http"}, "1": "one", "true": "yes"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("decodeVars was incorrect, got: %#v, want: %#v", actual, expected)
+	}
+
+	if _, err := decodeVars("vars.yml", []byte("- a\n- b")); err == nil {
+		t.Errorf("decodeVars of a list succeeded, but was expected to fail")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"net/url"
+	"strings"
+)
+
+func base64Encoder(enc *base64.Encoding) func(string) string {
+	return func(in string) string {
+		return enc.EncodeToString([]byte(in))
+	}
+}
+
+func base64Decoder(name string, enc *base64.Encoding) func(string) (string, error) {
+	return func(in string) (string, error) {
+		b, err := enc.DecodeString(strings.TrimSpace(in))
+		if err != nil {
+			return "", fmt.Errorf("%s: failed to decode: %w", name, err)
+		}
+		return string(b), nil
+	}
+}
+
+func base32Encode(in string) string {
+	return base32.StdEncoding.EncodeToString([]byte(in))
+}
+
+func base32Decode(in string) (string, error) {
+	b, err := base32.StdEncoding.DecodeString(strings.TrimSpace(in))
+	if err != nil {
+		return "", fmt.Errorf("b32dec: failed to decode: %w", err)
+	}
+	return string(b), nil
+}
+
+func hexEncode(in string) string {
+	return hex.EncodeToString([]byte(in))
+}
+
+func hexDecode(in string) (string, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(in))
+	if err != nil {
+		return "", fmt.Errorf("hexdec: failed to decode: %w", err)
+	}
+	return string(b), nil
+}
+
+func urlUnquery(in string) (string, error) {
+	s, err := url.QueryUnescape(in)
+	if err != nil {
+		return "", fmt.Errorf("urlunquery: failed to unescape: %w", err)
+	}
+	return s, nil
+}
+
+var hashes = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+func hashSum(algorithm string) func(string) string {
+	return func(in string) string {
+		h := hashes[algorithm]()
+		h.Write([]byte(in))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+func crc32Sum(in string) string {
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(in)))
+}
+
+// hmacSum returns hex-encoded HMAC of value using the given hash algorithm and key.
+// Usage: {{ .payload | hmac "sha256" .key }}.
+func hmacSum(algorithm, key, in string) (string, error) {
+	newHash, ok := hashes[strings.ToLower(algorithm)]
+	if !ok {
+		return "", fmt.Errorf("hmac: unsupported algorithm %q, expected sha1, sha256 or sha512", algorithm)
+	}
+	h := hmac.New(newHash, []byte(key))
+	h.Write([]byte(in))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(string) string
+		in       string
+		expected string
+	}{
+		{"b64urlenc", base64Encoder(base64.URLEncoding), "??>", "Pz8-"},
+		{"b64rawenc", base64Encoder(base64.RawStdEncoding), "hi", "aGk"},
+		{"b32enc", base32Encode, "hi", "NBUQ===="},
+		{"hex", hexEncode, "hi", "6869"},
+		{"sha1sum", hashSum("sha1"), "hello", "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d"},
+		{"sha256sum", hashSum("sha256"), "hello", "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{"sha512sum", hashSum("sha512"), "", "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e"},
+		{"crc32", crc32Sum, "hello", "3610a686"},
+	}
+
+	for _, tt := range tests {
+		actual := tt.fn(tt.in)
+		if actual != tt.expected {
+			t.Errorf("%s(%q) was incorrect, got: %q, want: %q.", tt.name, tt.in, actual, tt.expected)
+		}
+	}
+}
+
+func TestDecoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(string) (string, error)
+		in       string
+		expected string
+	}{
+		{"b64dec", base64Decoder("b64dec", base64.StdEncoding), "aGVsbG8=", "hello"},
+		{"b64dec", base64Decoder("b64dec", base64.StdEncoding), "aGVsbG8=\n", "hello"},
+		{"b64urldec", base64Decoder("b64urldec", base64.URLEncoding), "Pz8-", "??>"},
+		{"b64rawdec", base64Decoder("b64rawdec", base64.RawStdEncoding), "aGk", "hi"},
+		{"b32dec", base32Decode, "NBUQ====", "hi"},
+		{"hexdec", hexDecode, "6869", "hi"},
+		{"urlunquery", urlUnquery, "a+b%26c", "a b&c"},
+	}
+
+	for _, tt := range tests {
+		actual, err := tt.fn(tt.in)
+		if err != nil || actual != tt.expected {
+			t.Errorf("%s(%q) was incorrect, got: %q (%v), want: %q.", tt.name, tt.in, actual, err, tt.expected)
+		}
+	}
+
+	invalid := []struct {
+		name string
+		fn   func(string) (string, error)
+		in   string
+	}{
+		{"b64dec", base64Decoder("b64dec", base64.StdEncoding), "not base64!"},
+		{"b32dec", base32Decode, "hi!"},
+		{"hexdec", hexDecode, "zz"},
+		{"urlunquery", urlUnquery, "%zz"},
+	}
+	for _, tt := range invalid {
+		if _, err := tt.fn(tt.in); err == nil {
+			t.Errorf("%s(%q) expected error, got nil", tt.name, tt.in)
+		}
+	}
+}
+
+func TestHmacSum(t *testing.T) {
+	tests := []struct {
+		algorithm, key, in string
+		expected           string
+	}{
+		{"sha256", "key", "The quick brown fox jumps over the lazy dog", "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+		{"SHA1", "key", "The quick brown fox jumps over the lazy dog", "de7c9b85b8b78aa6bc8a7a36f70a90701c9db4d9"},
+	}
+
+	for _, tt := range tests {
+		actual, err := hmacSum(tt.algorithm, tt.key, tt.in)
+		if err != nil || actual != tt.expected {
+			t.Errorf("hmacSum(%q, %q, %q) was incorrect, got: %q (%v), want: %q.", tt.algorithm, tt.key, tt.in, actual, err, tt.expected)
+		}
+	}
+
+	if _, err := hmacSum("md4", "key", "value"); err == nil {
+		t.Errorf("hmacSum with unsupported algorithm expected error, got nil")
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var errOutsideRoot = errors.New("path is outside of the root directory")
+
+// fileReader resolves paths used by template functions relative to the
+// template directory and makes sure they stay inside the root directory
+// (INPUT_ROOT, GITHUB_WORKSPACE or the current directory), following symlinks.
+type fileReader struct {
+	dir  string
+	root string
+}
+
+func newFileReader(templateFilePath string) *fileReader {
+	root := os.Getenv("INPUT_ROOT")
+	if root == "" {
+		root = os.Getenv("GITHUB_WORKSPACE")
+	}
+	if root == "" {
+		root = "."
+	}
+	return &fileReader{
+		dir:  filepath.Dir(templateFilePath),
+		root: root,
+	}
+}
+
+// funcs returns template functions bound to the reader.
+func (r *fileReader) funcs() template.FuncMap {
+	return template.FuncMap{
+		"readFile":   r.readFile,
+		"readLines":  r.readLines,
+		"glob":       r.glob,
+		"fileExists": r.fileExists,
+		"readYAML":   r.dataReader("yaml"),
+		"readJSON":   r.dataReader("json"),
+		"readTOML":   r.dataReader("toml"),
+		"readCSV":    r.dataReader("csv"),
+	}
+}
+
+// resolve returns real path of the file, with all symlinks evaluated.
+func (r *fileReader) resolve(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.dir, path)
+	}
+
+	// check the path itself before touching the file system,
+	// so errors don't tell whether files outside of the root exist
+	absRoot, err := filepath.Abs(r.root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve root directory %q: %w", r.root, err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if !isInside(absRoot, absPath) {
+		return "", fmt.Errorf("%q: %w", path, errOutsideRoot)
+	}
+
+	root, err := realPath(r.root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve root directory %q: %w", r.root, err)
+	}
+	resolved, err := realPath(path)
+	if err != nil {
+		return "", err
+	}
+	if !isInside(root, resolved) {
+		return "", fmt.Errorf("%q: %w", path, errOutsideRoot)
+	}
+	return resolved, nil
+}
+
+func isInside(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func realPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// readFile returns content of the file.
+// Usage: {{ readFile "nginx.conf" | indent 4 }}.
+func (r *fileReader) readFile(path string) (string, error) {
+	b, err := r.read(path)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (r *fileReader) read(path string) ([]byte, error) {
+	resolved, err := r.resolve(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	b, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %q: %w", path, err)
+	}
+	return b, nil
+}
+
+// readLines returns lines of the file, without line endings.
+// Usage: {{ range readLines "hosts.txt" }}.
+func (r *fileReader) readLines(path string) ([]string, error) {
+	s, err := r.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s = strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	if s == "" {
+		return []string{}, nil
+	}
+	return strings.Split(s, "\n"), nil
+}
+
+// glob returns sorted paths matching the pattern, relative to the template directory.
+// Usage: {{ range glob "conf/*.conf" }}{{ readFile . }}{{ end }}.
+func (r *fileReader) glob(pattern string) ([]string, error) {
+	relative := !filepath.IsAbs(pattern)
+	if relative {
+		pattern = filepath.Join(r.dir, pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob %q: %w", pattern, err)
+	}
+
+	result := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if _, err := r.resolve(match); err != nil {
+			return nil, fmt.Errorf("failed to glob %q: %w", pattern, err)
+		}
+		if relative {
+			if rel, err := filepath.Rel(r.dir, match); err == nil {
+				match = rel
+			}
+		}
+		result = append(result, filepath.ToSlash(match))
+	}
+	return result, nil
+}
+
+// fileExists reports whether the file exists.
+// Usage: {{ if fileExists "extra.conf" }}.
+func (r *fileReader) fileExists(path string) (bool, error) {
+	_, err := r.resolve(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// dataReader returns function that reads and parses file in the given format.
+// Usage: {{ $env := readYAML (printf "envs/%s.yml" .env) }}.
+func (r *fileReader) dataReader(format string) func(string) (interface{}, error) {
+	return func(path string) (interface{}, error) {
+		b, err := r.read(path)
+		if err != nil {
+			return nil, err
+		}
+		result, err := decodeData(format, b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file %q: %w", path, err)
+		}
+		return result, nil
+	}
+}
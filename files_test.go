@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setupRoot creates the following tree and sets it as INPUT_ROOT:
+//
+//	root/templates/template.txt
+//	root/templates/conf/a.conf
+//	root/templates/conf/b.conf
+//	root/templates/link.conf -> ../../secret.txt
+//	root/shared.txt
+//	secret.txt
+func setupRoot(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	files := map[string]string{
+		"root/templates/template.txt": "{{ readFile \"conf/a.conf\" }}",
+		"root/templates/conf/a.conf":  "a = 1\n",
+		"root/templates/conf/b.conf":  "b = 2\r\nc = 3\n",
+		"root/shared.txt":             "shared",
+		"secret.txt":                  "secret",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("../../secret.txt", filepath.Join(root, "templates", "link.conf")); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+
+	t.Setenv("INPUT_ROOT", root)
+	return root
+}
+
+func TestFileReader(t *testing.T) {
+	root := setupRoot(t)
+	r := newFileReader(filepath.Join(root, "templates", "template.txt"))
+
+	content, err := r.readFile("conf/a.conf")
+	if err != nil || content != "a = 1\n" {
+		t.Errorf("readFile(%q) was incorrect, got: %q, %v", "conf/a.conf", content, err)
+	}
+
+	content, err = r.readFile("../shared.txt")
+	if err != nil || content != "shared" {
+		t.Errorf("readFile(%q) was incorrect, got: %q, %v", "../shared.txt", content, err)
+	}
+
+	lines, err := r.readLines("conf/b.conf")
+	if err != nil || !reflect.DeepEqual(lines, []string{"b = 2", "c = 3"}) {
+		t.Errorf("readLines(%q) was incorrect, got: %q, %v", "conf/b.conf", lines, err)
+	}
+
+	matches, err := r.glob("conf/*.conf")
+	if err != nil || !reflect.DeepEqual(matches, []string{"conf/a.conf", "conf/b.conf"}) {
+		t.Errorf("glob(%q) was incorrect, got: %q, %v", "conf/*.conf", matches, err)
+	}
+
+	exists, err := r.fileExists("conf/a.conf")
+	if err != nil || !exists {
+		t.Errorf("fileExists(%q) was incorrect, got: %v, %v", "conf/a.conf", exists, err)
+	}
+	exists, err = r.fileExists("conf/missing.conf")
+	if err != nil || exists {
+		t.Errorf("fileExists(%q) was incorrect, got: %v, %v", "conf/missing.conf", exists, err)
+	}
+}
+
+func TestFileReaderOutsideRoot(t *testing.T) {
+	root := setupRoot(t)
+	r := newFileReader(filepath.Join(root, "templates", "template.txt"))
+
+	for _, path := range []string{"../../secret.txt", "link.conf", filepath.Join(root, "..", "secret.txt")} {
+		if _, err := r.readFile(path); !errors.Is(err, errOutsideRoot) {
+			t.Errorf("readFile(%q) expected error %q, got: %v", path, errOutsideRoot, err)
+		}
+	}
+
+	// existing and missing files outside of the root are not told apart
+	for _, path := range []string{"link.conf", "../../secret.txt", "../../missing.txt"} {
+		if _, err := r.fileExists(path); !errors.Is(err, errOutsideRoot) {
+			t.Errorf("fileExists(%q) expected error %q, got: %v", path, errOutsideRoot, err)
+		}
+	}
+
+	if _, err := r.glob("*.conf"); !errors.Is(err, errOutsideRoot) {
+		t.Errorf("glob(%q) expected error %q, got: %v", "*.conf", errOutsideRoot, err)
+	}
+}
+
+func TestRenderTemplateReadFile(t *testing.T) {
+	root := setupRoot(t)
+
+	output, _, err := renderTemplate(filepath.Join(root, "templates", "template.txt"), vars{})
+	if err != nil {
+		t.Fatalf("renderTemplate returned an error: %v", err)
+	}
+	if output != "a = 1\n" {
+		t.Errorf("renderTemplate was incorrect, got: %q, want: %q.", output, "a = 1\n")
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatter is a YAML block at the beginning of a template, between "---" lines:
+//
+//	---
+//	vars:
+//	  replicas: 1
+//	output: "out/{{ .app }}.yml"
+//	format: yaml
+//	required: [app, image]
+//	---
+type frontMatter struct {
+	Vars     vars     `yaml:"vars"`
+	Output   string   `yaml:"output"`
+	Format   string   `yaml:"format"`
+	Required []string `yaml:"required"`
+
+	lines int // number of lines taken by the block, including "---" lines
+}
+
+// frontMatterFields are keys that make a leading YAML document front matter.
+var frontMatterFields = []string{"vars", "output", "format", "required"}
+
+// splitFrontMatter returns front matter and the rest of the template.
+// A leading YAML document is only treated as front matter if it has
+// at least one front matter field, so templates of multi-document YAML files
+// starting with "---" are left as is. Front matter with unknown fields
+// (e.g. misspelled ones) is an error.
+func splitFrontMatter(text string) (*frontMatter, string, error) {
+	normalized := strings.ReplaceAll(text, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return nil, text, nil
+	}
+
+	rest := normalized[len("---\n"):]
+	var block, body string
+	switch {
+	case strings.HasPrefix(rest, "---\n"):
+		block, body = "", rest[len("---\n"):]
+	default:
+		i := strings.Index(rest, "\n---\n")
+		if i == -1 {
+			if !strings.HasSuffix(rest, "\n---") {
+				return nil, text, nil
+			}
+			i = len(rest) - len("\n---")
+		}
+		block = rest[:i]
+		body = strings.TrimPrefix(rest[i+len("\n---"):], "\n")
+	}
+
+	fm := frontMatter{
+		lines: strings.Count(normalized[:len(normalized)-len(body)], "\n"),
+	}
+	if strings.TrimSpace(block) == "" {
+		return &fm, body, nil
+	}
+
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal([]byte(block), &fields); err != nil {
+		return nil, text, nil
+	}
+	isFrontMatter := false
+	for _, field := range frontMatterFields {
+		if _, ok := fields[field]; ok {
+			isFrontMatter = true
+		}
+	}
+	if !isFrontMatter {
+		return nil, text, nil
+	}
+
+	d := yaml.NewDecoder(strings.NewReader(block))
+	d.KnownFields(true)
+	if err := d.Decode(&fm); err != nil {
+		return nil, "", fmt.Errorf("failed to parse front matter: %w", err)
+	}
+	return &fm, body, nil
+}
+
+// padding returns template text that renders nothing but takes the same
+// number of lines as the front matter block, so template errors point
+// to the lines of the original file.
+func (fm *frontMatter) padding() string {
+	if fm.lines == 0 {
+		return ""
+	}
+	return "{{/*" + strings.Repeat("\n", fm.lines) + "*/}}"
+}
+
+// checkRequired returns error listing required vars (may be dot-separated paths) missing in v.
+func (fm *frontMatter) checkRequired(v vars) error {
+	var missing []string
+	for _, name := range fm.Required {
+		if value, ok := lookupPath(v, name); !ok || value == nil {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required vars: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// checkFormat makes sure rendered output is well-formed in the front matter format.
+func (fm *frontMatter) checkFormat(output string) error {
+	switch fm.Format {
+	case "", "text":
+		return nil
+	case "json":
+		if !json.Valid([]byte(output)) {
+			return fmt.Errorf("rendered output is not valid JSON")
+		}
+	case "yaml":
+		d := yaml.NewDecoder(bytes.NewReader([]byte(output)))
+		for {
+			var doc interface{}
+			err := d.Decode(&doc)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("rendered output is not valid YAML: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported format %q, expected text, json or yaml", fm.Format)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		in           string
+		expectedFM   *frontMatter
+		expectedBody string
+	}{
+		{
+			"---\nformat: json\nrequired: [a]\n---\n{}\n",
+			&frontMatter{Format: "json", Required: []string{"a"}, lines: 4},
+			"{}\n",
+		},
+		{
+			"---\r\noutput: out.txt\r\n---\r\nbody",
+			&frontMatter{Output: "out.txt", lines: 3},
+			"body",
+		},
+		{
+			"---\n---\nbody",
+			&frontMatter{lines: 2},
+			"body",
+		},
+		{
+			"---\nformat: text\n---",
+			&frontMatter{Format: "text", lines: 2},
+			"",
+		},
+		{
+			"---\napiVersion: v1\nkind: ConfigMap\n---\napiVersion: v1\n",
+			nil,
+			"---\napiVersion: v1\nkind: ConfigMap\n---\napiVersion: v1\n",
+		},
+		{
+			"---\nname: {{ .name }}\n",
+			nil,
+			"---\nname: {{ .name }}\n",
+		},
+		{
+			"Hello {{ .name }}",
+			nil,
+			"Hello {{ .name }}",
+		},
+	}
+
+	for _, tt := range tests {
+		fm, body, err := splitFrontMatter(tt.in)
+		if err != nil {
+			t.Errorf("splitFrontMatter(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(fm, tt.expectedFM) {
+			t.Errorf("splitFrontMatter(%q) front matter was incorrect, got: %+v, want: %+v.", tt.in, fm, tt.expectedFM)
+		}
+		if body != tt.expectedBody {
+			t.Errorf("splitFrontMatter(%q) body was incorrect, got: %q, want: %q.", tt.in, body, tt.expectedBody)
+		}
+	}
+}
+
+func TestSplitFrontMatterErrors(t *testing.T) {
+	for _, in := range []string{
+		"---\nformat: json\nouptut: out.json\n---\n{}\n",
+		"---\nrequired: a\n---\n",
+	} {
+		if _, _, err := splitFrontMatter(in); err == nil {
+			t.Errorf("splitFrontMatter(%q) expected error, got nil", in)
+		}
+	}
+}
+
+func TestRenderTemplateFrontMatterLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "template.txt")
+	if err := os.WriteFile(path, []byte("---\nformat: text\n---\nline 4\n{{ .missing }}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, _, err := renderTemplate(path, vars{"missing": "ok"})
+	if err != nil || output != "line 4\nok\n" {
+		t.Errorf("renderTemplate was incorrect, got: %q (%v)", output, err)
+	}
+
+	_, _, err = renderTemplate(path, vars{})
+	if err == nil || !strings.Contains(err.Error(), ":5:") {
+		t.Errorf("renderTemplate error should point to line 5, got: %v", err)
+	}
+}
+
+func TestRenderTemplateFrontMatter(t *testing.T) {
+	v := vars{"app": "web", "replicas": 3}
+	output, fm, err := renderTemplate("./testdata/frontmatter.yml", v)
+	if err != nil {
+		t.Fatalf("renderTemplate returned an error: %v", err)
+	}
+
+	if expected := "app: web\nreplicas: 3\ntag: latest\n"; output != expected {
+		t.Errorf("renderTemplate output was incorrect, got: %q, want: %q.", output, expected)
+	}
+	if fm.Output != "out/web.yml" {
+		t.Errorf("renderTemplate output path was incorrect, got: %q, want: %q.", fm.Output, "out/web.yml")
+	}
+	if _, ok := v["image"]; ok {
+		t.Errorf("renderTemplate modified vars: %v", v)
+	}
+
+	_, _, err = renderTemplate("./testdata/frontmatter.yml", vars{})
+	if err == nil || err.Error() != "missing required vars: app" {
+		t.Errorf("renderTemplate without required vars expected error, got: %v", err)
+	}
+
+	_, _, err = renderTemplate("./testdata/frontmatter.yml", vars{"app": "[unclosed"})
+	if err == nil {
+		t.Errorf("renderTemplate with invalid YAML output succeeded, but was expected to fail")
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	tests := []struct {
+		format, output string
+		valid          bool
+	}{
+		{"", "anything", true},
+		{"json", `{"a": 1}`, true},
+		{"json", `{"a": 1`, false},
+		{"yaml", "a: 1\n---\nb: 2\n", true},
+		{"yaml", "a: [1\n", false},
+		{"xml", "<a/>", false},
+	}
+
+	for _, tt := range tests {
+		err := (&frontMatter{Format: tt.format}).checkFormat(tt.output)
+		if (err == nil) != tt.valid {
+			t.Errorf("checkFormat(%q, %q) was incorrect, got: %v, want valid: %v.", tt.format, tt.output, err, tt.valid)
+		}
+	}
+}
@@ -3,7 +3,10 @@ module github.com/chuhlomin/render-template
 go 1.21
 
 require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/Masterminds/semver/v3 v3.5.0
 	github.com/caarlos0/env/v10 v10.0.0
+	github.com/yuin/goldmark v1.7.8
 	golang.org/x/text v0.18.0
 	gopkg.in/yaml.v3 v3.0.1
 )
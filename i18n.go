@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+	"gopkg.in/yaml.v3"
+)
+
+// translations holds messages loaded from the translations directory.
+// When nil, T formats the key itself.
+var translations catalog.Catalog
+
+// pluralForms are keys recognized as CLDR plural categories in catalog files.
+var pluralForms = map[string]bool{
+	"zero":  true,
+	"one":   true,
+	"two":   true,
+	"few":   true,
+	"many":  true,
+	"other": true,
+}
+
+// translate looks up key in the loaded catalog for the current locale
+// and formats it with args. When the message has plural forms,
+// the first argument selects the form. Missing keys are returned as is.
+// Usage: {{ T "releases" .count }}.
+func translate(key string, args ...interface{}) string {
+	if translations == nil {
+		return key
+	}
+	if translations.Context(defaultLocale, discardRenderer{}).Execute(key) == catalog.ErrNotFound {
+		return key
+	}
+	p := message.NewPrinter(defaultLocale, message.Catalog(translations))
+	return p.Sprintf(key, args...)
+}
+
+// discardRenderer is used to check whether message exists without rendering it.
+type discardRenderer struct{}
+
+func (discardRenderer) Render(string)       {}
+func (discardRenderer) Arg(int) interface{} { return nil }
+
+// loadTranslations reads YAML or JSON catalogs from dir.
+// Each file is named after its locale (e.g. "en.yml", "de-DE.json")
+// and maps keys to messages; a message is either a string
+// or a map of plural forms ("one", "other", "=0", ...).
+// Nested maps are flattened into dot-separated keys.
+func loadTranslations(dir string) (catalog.Catalog, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read translations directory %q: %w", dir, err)
+	}
+
+	b := catalog.NewBuilder()
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yml" && ext != ".yaml" && ext != ".json") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		tag, err := language.Parse(strings.TrimSuffix(entry.Name(), ext))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse locale of translations file %q: %w", path, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read translations file %q: %w", path, err)
+		}
+		var messages map[string]interface{}
+		if err = yaml.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("failed to parse translations file %q: %w", path, err)
+		}
+
+		if err = addMessages(b, tag, "", messages); err != nil {
+			return nil, fmt.Errorf("failed to load translations file %q: %w", path, err)
+		}
+	}
+
+	return b, nil
+}
+
+func addMessages(b *catalog.Builder, tag language.Tag, prefix string, messages map[string]interface{}) error {
+	for key, value := range messages {
+		key = prefix + key
+		switch v := value.(type) {
+		case string:
+			if err := b.SetString(tag, key, v); err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+		case map[string]interface{}:
+			if !isPluralMessage(v) {
+				if err := addMessages(b, tag, key+".", v); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := b.Set(tag, key, pluralMessage(v)); err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+		default:
+			return fmt.Errorf("key %q: unsupported message type %T", key, value)
+		}
+	}
+	return nil
+}
+
+func isPluralMessage(m map[string]interface{}) bool {
+	for form, value := range m {
+		if _, ok := value.(string); !ok {
+			return false
+		}
+		if !pluralForms[form] && !strings.HasPrefix(form, "=") && !strings.HasPrefix(form, "<") {
+			return false
+		}
+	}
+	return len(m) > 0
+}
+
+// pluralMessage builds a message selecting between plural forms
+// by the first argument. "other" must go last, as it matches any value.
+func pluralMessage(forms map[string]interface{}) catalog.Message {
+	keys := make([]string, 0, len(forms))
+	for form := range forms {
+		keys = append(keys, form)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[j] == "other" {
+			return keys[i] != "other"
+		}
+		if keys[i] == "other" {
+			return false
+		}
+		return keys[i] < keys[j]
+	})
+
+	cases := make([]interface{}, 0, len(forms)*2)
+	for _, form := range keys {
+		cases = append(cases, form, forms[form])
+	}
+	return plural.Selectf(1, "%d", cases...)
+}
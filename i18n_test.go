@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestTranslate(t *testing.T) {
+	cat, err := loadTranslations("./testdata/translations")
+	if err != nil {
+		t.Fatalf("loadTranslations returned an error: %v", err)
+	}
+
+	tests := []struct {
+		locale   string
+		key      string
+		args     []interface{}
+		expected string
+	}{
+		{"en", "greeting", []interface{}{"world"}, "Hello, world!"},
+		{"en", "releases", []interface{}{0}, "No releases"},
+		{"en", "releases", []interface{}{1}, "1 release"},
+		{"en", "releases", []interface{}{5}, "5 releases"},
+		{"en", "release.title", []interface{}{"v1.2"}, "Release v1.2"},
+		{"en-US", "releases", []interface{}{2}, "2 releases"},
+		{"ru", "greeting", []interface{}{"мир"}, "Привет, мир!"},
+		{"ru-RU", "releases", []interface{}{1}, "1 релиз"},
+		{"ru", "releases", []interface{}{3}, "3 релиза"},
+		{"ru", "releases", []interface{}{5}, "5 релизов"},
+		{"ru", "releases", []interface{}{21}, "21 релиз"},
+		{"en", "missing %d", []interface{}{1}, "missing %d"},
+	}
+
+	defer func(locale language.Tag) {
+		defaultLocale = locale
+		translations = nil
+	}(defaultLocale)
+	translations = cat
+
+	for _, tt := range tests {
+		defaultLocale = language.MustParse(tt.locale)
+		actual := translate(tt.key, tt.args...)
+		if actual != tt.expected {
+			t.Errorf("translate(%q, %v) in %q was incorrect, got: %q, want: %q.", tt.key, tt.args, tt.locale, actual, tt.expected)
+		}
+	}
+}
+
+func TestLoadTranslationsErrors(t *testing.T) {
+	if _, err := loadTranslations("./testdata/missing"); err == nil {
+		t.Errorf("loadTranslations for missing directory succeeded, but was expected to fail")
+	}
+}
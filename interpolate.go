@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// varTemplate is a string value in vars that contains template actions.
+type varTemplate struct {
+	path []string
+	tmpl *template.Template
+	deps [][]string // field chains referenced by the template, e.g. [["image", "tag"]]
+	all  bool       // references the whole vars with "."
+}
+
+// interpolateVars renders string values of vars that contain template actions
+// against vars themselves, e.g. `image: "{{ .registry }}/app:{{ .tag }}"`.
+// Values are rendered in dependency order; strings without actions are left as is.
+func interpolateVars(v vars, templateFilePath string) error {
+	funcs := templateFuncs(templateFilePath)
+
+	var templates []*varTemplate
+	var collect func(path []string, value interface{}) error
+	collect = func(path []string, value interface{}) error {
+		switch val := value.(type) {
+		case string:
+			if !strings.Contains(val, "{{") {
+				return nil
+			}
+			name := strings.Join(path, ".")
+			tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(val)
+			if err != nil {
+				return fmt.Errorf("failed to parse var %q: %w", name, err)
+			}
+			t := &varTemplate{path: path, tmpl: tmpl}
+			t.collectDeps(tmpl.Tree.Root, false)
+			templates = append(templates, t)
+		case map[string]interface{}, vars:
+			m, _ := toMap(val)
+			for _, k := range sortedKeys(m) {
+				if err := collect(appendPath(path, k), m[k]); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			for i, item := range val {
+				if err := collect(appendPath(path, strconv.Itoa(i)), item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := collect(nil, v); err != nil {
+		return err
+	}
+
+	const (
+		pending = iota
+		visiting
+		done
+	)
+	state := make(map[*varTemplate]int, len(templates))
+	var stack []*varTemplate
+
+	var resolve func(t *varTemplate) error
+	resolve = func(t *varTemplate) error {
+		switch state[t] {
+		case done:
+			return nil
+		case visiting:
+			chain := []string{}
+			for i := len(stack) - 1; i >= 0; i-- {
+				chain = append([]string{strings.Join(stack[i].path, ".")}, chain...)
+				if stack[i] == t {
+					break
+				}
+			}
+			chain = append(chain, strings.Join(t.path, "."))
+			return fmt.Errorf("vars reference each other: %s", strings.Join(chain, " -> "))
+		}
+
+		if t.dependsOn(t) {
+			return fmt.Errorf("var references itself: %s -> %s", t.tmpl.Name(), t.tmpl.Name())
+		}
+
+		state[t] = visiting
+		stack = append(stack, t)
+		for _, dep := range templates {
+			if dep != t && t.dependsOn(dep) {
+				if err := resolve(dep); err != nil {
+					return err
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+
+		var b bytes.Buffer
+		if err := t.tmpl.Execute(&b, v); err != nil {
+			return fmt.Errorf("failed to render var %q: %w", t.tmpl.Name(), err)
+		}
+		setPath(v, t.path, b.String())
+		state[t] = done
+		return nil
+	}
+
+	for _, t := range templates {
+		if err := resolve(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependsOn reports whether template references value of other template:
+// either the value itself (.image.tag) or one of its parents (.image).
+func (t *varTemplate) dependsOn(other *varTemplate) bool {
+	if t.all {
+		return t != other
+	}
+	for _, dep := range t.deps {
+		if hasPrefix(other.path, dep) || hasPrefix(dep, other.path) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectDeps walks template parse tree and records referenced fields.
+// Inside range and with blocks dot is bound to another value,
+// so only fields referenced via $ are recorded there.
+func (t *varTemplate) collectDeps(node parse.Node, rebound bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			t.collectDeps(child, rebound)
+		}
+	case *parse.ActionNode:
+		t.collectDeps(n.Pipe, rebound)
+	case *parse.IfNode:
+		t.collectDeps(n.Pipe, rebound)
+		t.collectDeps(n.List, rebound)
+		t.collectDeps(n.ElseList, rebound)
+	case *parse.RangeNode:
+		t.collectDeps(n.Pipe, rebound)
+		t.collectDeps(n.List, true)
+		t.collectDeps(n.ElseList, rebound)
+	case *parse.WithNode:
+		t.collectDeps(n.Pipe, rebound)
+		t.collectDeps(n.List, true)
+		t.collectDeps(n.ElseList, rebound)
+	case *parse.TemplateNode:
+		t.collectDeps(n.Pipe, rebound)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			t.collectDeps(cmd, rebound)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			t.collectDeps(arg, rebound)
+		}
+	case *parse.ChainNode:
+		t.collectDeps(n.Node, rebound)
+	case *parse.FieldNode:
+		if !rebound {
+			t.deps = append(t.deps, n.Ident)
+		}
+	case *parse.DotNode:
+		if !rebound {
+			t.all = true
+		}
+	case *parse.VariableNode:
+		switch {
+		case len(n.Ident) > 1 && n.Ident[0] == "$":
+			t.deps = append(t.deps, n.Ident[1:])
+		case n.Ident[0] == "$":
+			t.all = true
+		}
+	}
+}
+
+func appendPath(path []string, key string) []string {
+	result := make([]string, len(path), len(path)+1)
+	copy(result, path)
+	return append(result, key)
+}
+
+func hasPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// setPath replaces value at path in nested maps and lists.
+func setPath(v vars, path []string, value interface{}) {
+	var current interface{} = map[string]interface{}(v)
+	for i, key := range path {
+		isLast := i == len(path)-1
+		switch c := current.(type) {
+		case map[string]interface{}:
+			if isLast {
+				c[key] = value
+				return
+			}
+			current = c[key]
+		case vars:
+			if isLast {
+				c[key] = value
+				return
+			}
+			current = c[key]
+		case []interface{}:
+			idx, _ := strconv.Atoi(key)
+			if isLast {
+				c[idx] = value
+				return
+			}
+			current = c[idx]
+		}
+	}
+}
+
+// copyVars returns deep copy of vars, so values can be replaced in place
+// without changing maps shared with other vars.
+func copyVars(v vars) vars {
+	return copyValue(v).(vars)
+}
+
+func copyValue(value interface{}) interface{} {
+	switch val := value.(type) {
+	case vars:
+		result := make(vars, len(val))
+		for k, item := range val {
+			result[k] = copyValue(item)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			result[k] = copyValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			result[i] = copyValue(item)
+		}
+		return result
+	}
+	return value
+}
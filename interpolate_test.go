@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInterpolateVars(t *testing.T) {
+	v := vars{
+		"registry": "ghcr.io/{{ .org }}",
+		"org":      "acme",
+		"tag":      "v1",
+		"image":    "{{ .registry }}/app:{{ .tag }}",
+		"plain":    "no actions here",
+		"count":    3,
+		"images": map[string]interface{}{
+			"app":     "{{ .image }}",
+			"sidecar": "{{ .images.app }}-sidecar",
+		},
+		"regions": []interface{}{"{{ .org }}-east", "west"},
+		"all":     "{{ range .regions }}{{ . }};{{ end }}",
+	}
+
+	if err := interpolateVars(v, "./testdata/template.txt"); err != nil {
+		t.Fatalf("interpolateVars returned an error: %v", err)
+	}
+
+	expected := vars{
+		"registry": "ghcr.io/acme",
+		"org":      "acme",
+		"tag":      "v1",
+		"image":    "ghcr.io/acme/app:v1",
+		"plain":    "no actions here",
+		"count":    3,
+		"images": map[string]interface{}{
+			"app":     "ghcr.io/acme/app:v1",
+			"sidecar": "ghcr.io/acme/app:v1-sidecar",
+		},
+		"regions": []interface{}{"acme-east", "west"},
+		"all":     "acme-east;west;",
+	}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("interpolateVars was incorrect, got: %v, want: %v.", v, expected)
+	}
+}
+
+func TestInterpolateVarsErrors(t *testing.T) {
+	tests := []struct {
+		vars     vars
+		expected string
+	}{
+		{
+			vars{"a": "{{ .b }}", "b": "{{ .c }}", "c": "{{ .a }}"},
+			"vars reference each other: a -> b -> c -> a",
+		},
+		{
+			vars{"a": "x{{ .a }}"},
+			"var references itself: a -> a",
+		},
+		{
+			vars{"image": map[string]interface{}{"name": "{{ .image }}"}},
+			"var references itself: image.name -> image.name",
+		},
+		{
+			vars{"a": "{{ .missing }}"},
+			`failed to render var "a": template: a:1:3: executing "a" at <.missing>: map has no entry for key "missing"`,
+		},
+		{
+			vars{"a": "{{ if }}"},
+			`failed to parse var "a": template: a:1: missing value for if`,
+		},
+	}
+
+	for _, tt := range tests {
+		err := interpolateVars(tt.vars, "./testdata/template.txt")
+		if err == nil {
+			t.Errorf("interpolateVars(%v) succeeded, but was expected to fail", tt.vars)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("interpolateVars(%v) expected error: %q, got: %q", tt.vars, tt.expected, err)
+		}
+	}
+}
@@ -2,11 +2,15 @@ package main
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"reflect"
 	"strconv"
@@ -17,27 +21,85 @@ import (
 
 	"github.com/caarlos0/env/v10"
 	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 	"gopkg.in/yaml.v3"
 )
 
 type vars map[string]interface{}
 
 type config struct {
-	Template   string `env:"INPUT_TEMPLATE" envDefault:".kube.yml"`
-	Vars       vars   `env:"INPUT_VARS" envDefault:""`
-	VarsPath   string `env:"INPUT_VARS_PATH" envDefault:""`
-	ResultPath string `env:"INPUT_RESULT_PATH" envDefault:""`
+	Template         string `env:"INPUT_TEMPLATE" envDefault:""`
+	Vars             vars   `env:"INPUT_VARS" envDefault:""`
+	VarsPath         string `env:"INPUT_VARS_PATH" envDefault:""`
+	ResultPath       string `env:"INPUT_RESULT_PATH" envDefault:""`
+	VarsPathTemplate bool   `env:"INPUT_VARS_PATH_TEMPLATE" envDefault:"false"`
+	InterpolateVars  bool   `env:"INPUT_INTERPOLATE_VARS" envDefault:"false"`
+	Locale           string `env:"INPUT_LOCALE" envDefault:""`
+	Translations     string `env:"INPUT_TRANSLATIONS" envDefault:""`
+	Config           string `env:"INPUT_CONFIG" envDefault:""`
+	Foreach          string `env:"INPUT_FOREACH" envDefault:""`
+	Split            bool   `env:"INPUT_SPLIT" envDefault:"false"`
+	ResultOutputs    bool   `env:"INPUT_RESULT_OUTPUTS" envDefault:"false"`
+	Summary          bool   `env:"INPUT_SUMMARY" envDefault:"false"`
+	ExportEnv        bool   `env:"INPUT_EXPORT_ENV" envDefault:"false"`
+	SecretVars       string `env:"INPUT_SECRET_VARS" envDefault:""`
+	MaskOutput       bool   `env:"INPUT_MASK_OUTPUT" envDefault:"false"`
+	Output           string `env:"INPUT_OUTPUT" envDefault:"full"`
+	OutputMaxSize    int    `env:"INPUT_OUTPUT_MAX_SIZE" envDefault:"1048576"`
+	FileMode         string `env:"INPUT_FILE_MODE" envDefault:""`
+	PreserveFileMode bool   `env:"INPUT_PRESERVE_FILE_MODE" envDefault:"false"`
+}
+
+const defaultTemplate = ".kube.yml"
+
+// check returns error for inputs that can't be used together,
+// so they are not silently ignored.
+func (c config) check() error {
+	if c.Config != "" {
+		inputs := []struct {
+			name string
+			set  bool
+		}{
+			{"template", c.Template != ""},
+			{"vars_path", c.VarsPath != ""},
+			{"result_path", c.ResultPath != ""},
+			{"foreach", c.Foreach != ""},
+		}
+		for _, input := range inputs {
+			if input.set {
+				return fmt.Errorf("%s input can't be used with config, set it for jobs in the config file", input.name)
+			}
+		}
+	}
+
+	if c.Config != "" || c.Foreach != "" || c.Split {
+		inputs := []struct {
+			name string
+			set  bool
+		}{
+			{"result_outputs", c.ResultOutputs},
+			{"summary", c.Summary},
+			{"export_env", c.ExportEnv},
+		}
+		for _, input := range inputs {
+			if input.set {
+				return fmt.Errorf("%s input can't be used with config, foreach or split", input.name)
+			}
+		}
+		if c.Output == outputPath || c.Output == outputSHA256 {
+			return fmt.Errorf("output mode %q can't be used with config, foreach or split, result output is the list of written files", c.Output)
+		}
+	}
+	return nil
 }
 
 func main() {
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		fmt.Printf("::error::%v", err)
 		os.Exit(1)
 	}
 }
 
-func run() error {
+func run(args []string) error {
 	var c config
 	parsers := map[reflect.Type]env.ParserFunc{
 		reflect.TypeOf(vars{}): varsParser,
@@ -46,31 +108,104 @@ func run() error {
 		return err
 	}
 
-	if c.VarsPath != "" {
-		varsFile, err := os.ReadFile(c.VarsPath)
+	flags := flag.NewFlagSet("render-template", flag.ContinueOnError)
+	flags.StringVar(&c.Config, "config", c.Config, "path to project config file with render jobs")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if c.Locale != "" {
+		tag, err := language.Parse(c.Locale)
 		if err != nil {
-			return fmt.Errorf("failed to read vars file %q: %w", c.VarsPath, err)
+			return fmt.Errorf("failed to parse locale %q: %w", c.Locale, err)
 		}
-		var varsFromFile vars
-		if err = yaml.Unmarshal(varsFile, &varsFromFile); err != nil {
-			return fmt.Errorf("failed to parse vars file %q: %w", c.VarsPath, err)
+		defaultLocale = tag
+	}
+
+	if c.Translations != "" {
+		cat, err := loadTranslations(c.Translations)
+		if err != nil {
+			return err
 		}
-		c.Vars = mergeVars(c.Vars, varsFromFile)
+		translations = cat
 	}
 
-	output, err := renderTemplate(c.Template, c.Vars)
+	if err := checkOutputMode(c.Output); err != nil {
+		return err
+	}
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	if c.Config != "" {
+		return runProject(c)
+	}
+
+	if c.Template == "" {
+		c.Template = defaultTemplate
+	}
+
+	j := job{
+		Template:   c.Template,
+		Vars:       c.Vars,
+		ResultPath: c.ResultPath,
+		Foreach:    c.Foreach,
+	}
+	if c.VarsPath != "" {
+		j.VarsPath = stringList{c.VarsPath}
+	}
+
+	if c.Foreach != "" || c.Split {
+		paths, changed, err := j.run(c, nil)
+		if err != nil {
+			return err
+		}
+		return writePathsOutput(c, paths, changed)
+	}
+
+	mode, keepMode, err := j.fileMode(c)
 	if err != nil {
-		return fmt.Errorf("failed to render template: %w", err)
+		return err
 	}
 
-	if err := writeOutput(output); err != nil {
+	results, err := j.render(c, nil)
+	if err != nil {
 		return err
 	}
+	r := results[0]
 
-	if c.ResultPath != "" {
-		err := os.WriteFile(c.ResultPath, []byte(output), 0o644)
+	if c.ResultOutputs {
+		if err := setResultOutputs(r.output); err != nil {
+			return err
+		}
+	}
+
+	changed := false
+	if r.path != "" {
+		changed, err = writeFile(r.path, []byte(r.output), mode, keepMode)
 		if err != nil {
-			return fmt.Errorf("failed to write file %q: %w", c.ResultPath, err)
+			return fmt.Errorf("failed to write file %q: %w", r.path, err)
+		}
+	}
+	setStepOutput("changed", strconv.FormatBool(changed))
+
+	result, err := resultOutput(c.Output, c.OutputMaxSize, r)
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(result); err != nil {
+		return err
+	}
+
+	if c.Summary {
+		if err := writeSummary(r.output); err != nil {
+			return err
+		}
+	}
+
+	if c.ExportEnv {
+		if err := exportEnv(r.output); err != nil {
+			return err
 		}
 	}
 
@@ -133,21 +268,90 @@ var funcMap = template.FuncMap{
 	"mdlink": func(text, url string) string {
 		return fmt.Sprintf("[%s](%s)", text, url)
 	},
-	"number": func(in string) string {
-		p := message.NewPrinter(language.English)
-		d, err := strconv.ParseInt(in, 10, 64)
-		if err != nil {
-			log.Printf("failed to parse number %q: %v", in, err)
-			return in
-		}
-		return p.Sprintf("%d", d)
-	},
-	"base64": func(in string) string {
-		return base64.StdEncoding.EncodeToString([]byte(in))
-	},
+	"mdEscape":   mdEscape,
+	"mdTable":    mdTable,
+	"mdCode":     mdCode,
+	"mdList":     mdList,
+	"mdDetails":  mdDetails,
+	"mdAnchor":   mdAnchor,
+	"markdown":   markdownToHTML,
+	"number":     formatNumber,
+	"currency":   formatCurrency,
+	"percent":    formatPercent,
+	"compact":    formatCompact,
+	"bytes":      formatBytes,
+	"bytesSI":    formatBytesSI,
+	"base64":     base64Encoder(base64.StdEncoding),
+	"b64enc":     base64Encoder(base64.StdEncoding),
+	"b64dec":     base64Decoder("b64dec", base64.StdEncoding),
+	"b64urlenc":  base64Encoder(base64.URLEncoding),
+	"b64urldec":  base64Decoder("b64urldec", base64.URLEncoding),
+	"b64rawenc":  base64Encoder(base64.RawStdEncoding),
+	"b64rawdec":  base64Decoder("b64rawdec", base64.RawStdEncoding),
+	"b32enc":     base32Encode,
+	"b32dec":     base32Decode,
+	"hex":        hexEncode,
+	"hexdec":     hexDecode,
+	"urlpath":    url.PathEscape,
+	"urlunquery": urlUnquery,
+	"sha1sum":    hashSum("sha1"),
+	"sha256sum":  hashSum("sha256"),
+	"sha512sum":  hashSum("sha512"),
+	"crc32":      crc32Sum,
+	"hmac":       hmacSum,
 	"split": func(sep string, in string) []string {
 		return strings.Split(in, sep)
 	},
+	"regexMatch":      regexMatch,
+	"regexFind":       regexFind,
+	"regexFindAll":    regexFindAll,
+	"regexReplaceAll": regexReplaceAll,
+	"regexSplit":      regexSplit,
+	"regexCaptures":   regexCaptures,
+	"T":               translate,
+	"title":           title,
+	"upper":           upper,
+	"lower":           lower,
+	"camelcase":       camelCase,
+	"snakecase":       snakeCase,
+	"kebabcase":       kebabCase,
+	"normalize":       normalize,
+	"slugify":         slugify,
+	"truncate":        truncate,
+	"indent":          indent,
+	"nindent":         nindent,
+	"semver":          parseSemver,
+	"semverCompare":   semverCompare,
+	"semverBump":      semverBump,
+	"semverSort":      semverSort,
+	"add":             add,
+	"sub":             sub,
+	"mul":             mul,
+	"div":             div,
+	"mod":             mod,
+	"max":             maxOf,
+	"min":             minOf,
+	"floor":           floor,
+	"ceil":            ceil,
+	"round":           round,
+	"int":             intValue,
+	"float":           floatValue,
+	"dict":            dict,
+	"list":            list,
+	"append":          appendList,
+	"keys":            keys,
+	"values":          values,
+	"sortAlpha":       sortAlpha,
+	"sortBy":          sortBy,
+	"uniq":            uniq,
+	"first":           first,
+	"last":            last,
+	"pluck":           pluck,
+	"groupBy":         groupBy,
+	"where":           where,
+	"merge":           merge,
+	"file":            fileMarker,
+	"setOutput":       setOutput,
 	"toJSON": func(in interface{}) string {
 		b, err := json.Marshal(in)
 		if err != nil {
@@ -158,71 +362,117 @@ var funcMap = template.FuncMap{
 	},
 }
 
-func renderTemplate(templateFilePath string, vars vars) (string, error) {
+// renderTemplate renders template file with vars.
+// Front matter of the template, if any, is returned with its output path rendered.
+func renderTemplate(templateFilePath string, v vars) (string, *frontMatter, error) {
 	b, err := os.ReadFile(templateFilePath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return "", fmt.Errorf("template file not found (%q)", templateFilePath)
+			return "", nil, fmt.Errorf("template file not found (%q)", templateFilePath)
 		}
 		if errors.Is(err, os.ErrPermission) {
-			return "", fmt.Errorf("have no permissions to read template file (%q)", templateFilePath)
+			return "", nil, fmt.Errorf("have no permissions to read template file (%q)", templateFilePath)
 		}
-		return "", fmt.Errorf("failed to read template %q: %w", templateFilePath, err)
+		return "", nil, fmt.Errorf("failed to read template %q: %w", templateFilePath, err)
+	}
+
+	fm, text, err := splitFrontMatter(string(b))
+	if err != nil {
+		return "", nil, err
+	}
+	if fm == nil {
+		fm = &frontMatter{}
+	}
+	text = fm.padding() + text
+	if fm.Vars != nil {
+		// copy vars, so front matter defaults don't leak to other templates
+		v = mergeVars(mergeVars(vars{}, v), fm.Vars)
+	}
+	if err := fm.checkRequired(v); err != nil {
+		return "", nil, err
 	}
 
-	tmpl, err := template.
-		New(templateFilePath).
-		Option("missingkey=error").
-		Funcs(funcMap).
-		Parse(string(b))
+	funcs := templateFuncs(templateFilePath)
+	result, err := executeTemplate(templateFilePath, text, funcs, v)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	if err := fm.checkFormat(result); err != nil {
+		return "", nil, err
 	}
 
-	var result bytes.Buffer
-	if err := tmpl.Execute(&result, vars); err != nil {
-		return "", err
+	if fm.Output != "" {
+		fm.Output, err = executeTemplate("output", fm.Output, funcs, v)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to render output path: %w", err)
+		}
 	}
 
-	return result.String(), nil
+	return result, fm, nil
 }
 
-func writeOutput(output string) error {
-	githubOutput := formatOutput("result", output)
-	if githubOutput == "" {
-		return nil
+// renderVarsFile executes vars file as a template before it is parsed,
+// so it may use conditionals, loops and template functions.
+// Values from `vars` input are available as data.
+func renderVarsFile(varsFilePath string, b []byte, v vars) ([]byte, error) {
+	if v == nil {
+		v = vars{}
 	}
 
-	path := os.Getenv("GITHUB_OUTPUT")
+	// environment is only available here, not in templates, where it could leak runner secrets
+	funcs := templateFuncs(varsFilePath)
+	funcs["env"] = os.Getenv
 
-	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	result, err := executeTemplate(varsFilePath, string(b), funcs, v)
 	if err != nil {
-		return fmt.Errorf(
-			"failed to open result file %q: %v. "+
-				"If you are using self-hosted runners "+
-				"make sure they are updated to version 2.297.0 or greater",
-			path,
-			err,
-		)
+		return nil, err
 	}
-	defer f.Close()
+	return []byte(result), nil
+}
 
-	if _, err = f.WriteString(githubOutput); err != nil {
-		return fmt.Errorf("failed to write result to file %q: %w", path, err)
+// writeOutput writes `result` and named outputs set by the template to GITHUB_OUTPUT.
+func writeOutput(output string) error {
+	var githubOutput strings.Builder
+	for _, o := range append([]namedOutput{{name: "result", value: output}}, stepOutputs...) {
+		line, err := formatOutput(o.name, o.value)
+		if err != nil {
+			return err
+		}
+		if line != "" {
+			githubOutput.WriteString(line)
+			githubOutput.WriteString("\n")
+		}
 	}
+	return appendGitHubFile("GITHUB_OUTPUT", githubOutput.String())
+}
 
-	return nil
+// newDelimiter returns random heredoc delimiter for multiline values,
+// so rendered content can't end the value early and inject other outputs.
+var newDelimiter = func() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate delimiter: %w", err)
+	}
+	return "ghadelimiter_" + hex.EncodeToString(b), nil
 }
 
-func formatOutput(name, value string) string {
+func formatOutput(name, value string) (string, error) {
 	if value == "" {
-		return ""
+		return "", nil
 	}
 
 	// if value contains new line, use multiline format
 	if bytes.ContainsRune([]byte(value), '\n') {
-		return fmt.Sprintf("%s<<OUTPUT\n%s\nOUTPUT", name, value)
+		delimiter, err := newDelimiter()
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(value, delimiter) {
+			return "", fmt.Errorf("value of %q contains delimiter %q", name, delimiter)
+		}
+		return fmt.Sprintf("%s<<%s\n%s\n%s", name, delimiter, value, delimiter), nil
 	}
 
-	return fmt.Sprintf("%s=%s", name, value)
+	return fmt.Sprintf("%s=%s", name, value), nil
 }
@@ -3,12 +3,17 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestFormatOutput(t *testing.T) {
+	stubDelimiter(t, "EOF")
+
 	tests := []struct {
 		in       string
 		expected string
@@ -16,17 +21,48 @@ func TestFormatOutput(t *testing.T) {
 		{"", ""},
 		{"text", "result=text"},
 		{"%", "result=%"},
-		{"some\ntext", "result<<OUTPUT\nsome\ntext\nOUTPUT"},
-		{"\n", "result<<OUTPUT\n\n\nOUTPUT"},
+		{"some\ntext", "result<<EOF\nsome\ntext\nEOF"},
+		{"\n", "result<<EOF\n\n\nEOF"},
 		{"\r", "result=\r"},
+		{"OUTPUT\nextra=1", "result<<EOF\nOUTPUT\nextra=1\nEOF"},
 	}
 
 	for _, tt := range tests {
-		actual := formatOutput("result", tt.in)
+		actual, err := formatOutput("result", tt.in)
+		if err != nil {
+			t.Errorf("formatOutput(%q) returned error: %v", tt.in, err)
+			continue
+		}
 		if actual != tt.expected {
 			t.Errorf("formatOutput(%q) was incorrect, got: %q, want: %q.", tt.in, actual, tt.expected)
 		}
 	}
+
+	if _, err := formatOutput("result", "a\nEOF\nextra=1"); err == nil {
+		t.Errorf("formatOutput of value with delimiter expected error, got nil")
+	}
+}
+
+func TestNewDelimiter(t *testing.T) {
+	a, err := newDelimiter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := newDelimiter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(a, "ghadelimiter_") || a == b {
+		t.Errorf("newDelimiter() returned %q and %q, want distinct random delimiters", a, b)
+	}
+}
+
+// stubDelimiter makes formatOutput use the fixed delimiter during the test.
+func stubDelimiter(t *testing.T, delimiter string) {
+	t.Helper()
+	orig := newDelimiter
+	newDelimiter = func() (string, error) { return delimiter, nil }
+	t.Cleanup(func() { newDelimiter = orig })
 }
 
 func TestVarsParser(t *testing.T) {
@@ -165,7 +201,7 @@ QUJD
 	t.Setenv("INPUT_TIMEZONE", "America/New_York")
 
 	for _, tt := range tests {
-		output, err := renderTemplate(tt.templateFilePath, tt.vars)
+		output, _, err := renderTemplate(tt.templateFilePath, tt.vars)
 		switch {
 		case err != nil:
 			if tt.expectedError == nil {
@@ -251,3 +287,149 @@ func TestMergeVars(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderVarsFile(t *testing.T) {
+	b, err := os.ReadFile("./testdata/vars/regions.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", "/home/runner")
+	rendered, err := renderVarsFile("./testdata/vars/regions.yml", b, vars{"env": "production"})
+	if err != nil {
+		t.Fatalf("renderVarsFile returned an error: %v", err)
+	}
+
+	actual, err := decodeVars("./testdata/vars/regions.yml", rendered)
+	if err != nil {
+		t.Fatalf("failed to parse rendered vars file %q: %v", rendered, err)
+	}
+	expected := vars{
+		"env": "production",
+		"regions": []interface{}{
+			map[string]interface{}{"name": "us-east-1", "replicas": 3},
+			map[string]interface{}{"name": "eu-west-1", "replicas": 3},
+		},
+		"home_set": true,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("renderVarsFile was incorrect, got: %#v, want: %#v.", actual, expected)
+	}
+
+	if _, err := executeTemplate("test", `{{ env "HOME" }}`, templateFuncs("test"), nil); err == nil {
+		t.Errorf("env function is available outside of vars file")
+	}
+
+	if _, err := renderVarsFile("./testdata/vars/regions.yml", b, nil); err == nil {
+		t.Errorf("renderVarsFile without required vars succeeded, but was expected to fail")
+	}
+}
+
+func TestConfigCheck(t *testing.T) {
+	valid := []config{
+		{Template: "a.yml", ResultOutputs: true, Summary: true, ExportEnv: true},
+		{Config: "c.yml", Vars: vars{"a": 1}},
+		{Foreach: ".items", ResultPath: "out/{{ .item }}.yml", Output: outputNone},
+		{Split: true, Output: outputFull},
+	}
+	for _, c := range valid {
+		if err := c.check(); err != nil {
+			t.Errorf("check() of %+v returned error: %v", c, err)
+		}
+	}
+
+	invalid := []config{
+		{Config: "c.yml", Template: "a.yml"},
+		{Config: "c.yml", VarsPath: "vars.yml"},
+		{Config: "c.yml", ResultPath: "out.yml"},
+		{Config: "c.yml", Foreach: ".items"},
+		{Config: "c.yml", Summary: true},
+		{Foreach: ".items", ResultOutputs: true},
+		{Split: true, ExportEnv: true},
+		{Foreach: ".items", Output: outputSHA256},
+		{Config: "c.yml", Output: outputPath},
+	}
+	for _, c := range invalid {
+		if err := c.check(); err == nil {
+			t.Errorf("check() of %+v expected error, got nil", c)
+		}
+	}
+}
+
+func TestRunChanged(t *testing.T) {
+	t.Cleanup(func() { stepOutputs = nil })
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+	result := filepath.Join(dir, "result.txt")
+
+	t.Setenv("INPUT_TEMPLATE", "testdata/template.txt")
+	t.Setenv("INPUT_VARS", "name: world")
+	t.Setenv("INPUT_RESULT_PATH", result)
+	t.Setenv("INPUT_OUTPUT", "none")
+	t.Setenv("GITHUB_OUTPUT", output)
+
+	tests := []struct {
+		mode     string
+		expected string
+	}{
+		{"", "changed=true\n"},     // created
+		{"", "changed=false\n"},    // same content
+		{"0600", "changed=true\n"}, // new mode
+	}
+	for _, tt := range tests {
+		t.Setenv("INPUT_FILE_MODE", tt.mode)
+		if err := os.WriteFile(output, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := run(nil); err != nil {
+			t.Fatalf("run() returned error: %v", err)
+		}
+		b, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.expected {
+			t.Errorf("run() output was incorrect, got: %q, want: %q.", b, tt.expected)
+		}
+	}
+	assertFile(t, result, "Hello world\n", 0o600)
+}
+
+func TestRunKeepsMode(t *testing.T) {
+	t.Cleanup(func() { stepOutputs = nil })
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+	if err := os.WriteFile(output, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result := filepath.Join(dir, "deploy.sh")
+	if err := os.WriteFile(result, []byte("old\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("INPUT_TEMPLATE", "testdata/template.txt")
+	t.Setenv("INPUT_VARS", "name: world")
+	t.Setenv("INPUT_RESULT_PATH", result)
+	t.Setenv("INPUT_OUTPUT", "none")
+	t.Setenv("GITHUB_OUTPUT", output)
+
+	// without file_mode existing file keeps its permissions,
+	// so unchanged content is not reported as changed
+	for _, expected := range []string{"changed=true\n", "changed=false\n"} {
+		if err := os.WriteFile(output, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := run(nil); err != nil {
+			t.Fatalf("run() returned error: %v", err)
+		}
+		assertFile(t, result, "Hello world\n", 0o755)
+
+		b, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expected {
+			t.Errorf("run() output was incorrect, got: %q, want: %q.", b, expected)
+		}
+	}
+}
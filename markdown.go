@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `{`, `\{`, `}`, `\}`,
+	`[`, `\[`, `]`, `\]`, `(`, `\(`, `)`, `\)`, `<`, `\<`, `>`, `\>`,
+	`#`, `\#`, `+`, `\+`, `-`, `\-`, `!`, `\!`, `|`, `\|`,
+)
+
+// mdEscape escapes characters that have special meaning in Markdown.
+// Usage: {{ .commitMessage | mdEscape }}.
+func mdEscape(in string) string {
+	return mdEscaper.Replace(in)
+}
+
+// mdCell escapes value to be placed in a table cell:
+// pipes would end the cell and new lines would end the row.
+func mdCell(in interface{}) string {
+	if in == nil {
+		return ""
+	}
+	s := strings.ReplaceAll(fmt.Sprint(in), "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+var mdAlignments = map[string]string{
+	"":       "---",
+	"l":      ":---",
+	"left":   ":---",
+	"c":      ":---:",
+	"center": ":---:",
+	"r":      "---:",
+	"right":  "---:",
+}
+
+// mdTable renders list of maps as a Markdown table.
+// Columns are given as "field" or "field:align" (left, center or right);
+// without columns, all fields are used in alphabetical order.
+// Usage: {{ .services | mdTable "name" "replicas:right" }}.
+func mdTable(args ...interface{}) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("mdTable: expected list of maps")
+	}
+	rows, err := toList(args[len(args)-1])
+	if err != nil {
+		return "", fmt.Errorf("mdTable: %w", err)
+	}
+
+	var fields, aligns []string
+	for _, arg := range args[:len(args)-1] {
+		spec, ok := arg.(string)
+		if !ok {
+			return "", fmt.Errorf("mdTable: column must be a string, got %T", arg)
+		}
+		field, align, _ := strings.Cut(spec, ":")
+		if _, ok := mdAlignments[align]; !ok {
+			return "", fmt.Errorf("mdTable: unsupported alignment %q for column %q", align, field)
+		}
+		fields = append(fields, field)
+		aligns = append(aligns, mdAlignments[align])
+	}
+
+	if len(fields) == 0 {
+		seen := map[string]interface{}{}
+		for _, row := range rows {
+			m, err := toMap(row)
+			if err != nil {
+				return "", fmt.Errorf("mdTable: %w", err)
+			}
+			for k := range m {
+				seen[k] = nil
+			}
+		}
+		fields = sortedKeys(seen)
+		for range fields {
+			aligns = append(aligns, mdAlignments[""])
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("| ")
+		b.WriteString(strings.Join(cells, " | "))
+		b.WriteString(" |\n")
+	}
+
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i] = mdCell(field)
+	}
+	writeRow(header)
+	writeRow(aligns)
+
+	for _, row := range rows {
+		m, err := toMap(row)
+		if err != nil {
+			return "", fmt.Errorf("mdTable: %w", err)
+		}
+		cells := make([]string, len(fields))
+		for i, field := range fields {
+			cells[i] = mdCell(m[field])
+		}
+		writeRow(cells)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// mdCode wraps string in a fenced code block, using a longer fence
+// if the string contains backticks itself.
+// Usage: {{ .config | mdCode "yaml" }}.
+func mdCode(lang, in string) string {
+	fence := "```"
+	for strings.Contains(in, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimSuffix(in, "\n") + "\n" + fence
+}
+
+// mdList renders list as Markdown bullet list.
+// Usage: {{ .changes | mdList }}.
+func mdList(in interface{}) (string, error) {
+	items, err := toList(in)
+	if err != nil {
+		return "", fmt.Errorf("mdList: %w", err)
+	}
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		// indent continuation lines so multiline items stay inside the list item
+		lines = append(lines, "- "+strings.ReplaceAll(fmt.Sprint(item), "\n", "\n  "))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// mdDetails wraps content into a collapsible section.
+// Usage: {{ .log | mdCode "" | mdDetails "Full log" }}.
+func mdDetails(summary, in string) string {
+	return fmt.Sprintf(
+		"<details>\n<summary>%s</summary>\n\n%s\n\n</details>",
+		html.EscapeString(summary),
+		strings.TrimSuffix(in, "\n"),
+	)
+}
+
+// mdAnchor returns link to a heading, generated the same way GitHub does:
+// lower case, punctuation removed and spaces replaced with hyphens.
+// Usage: {{ mdlink "Changes" (mdAnchor "Breaking changes") }}.
+func mdAnchor(heading string) string {
+	var b strings.Builder
+	b.WriteByte('#')
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+var markdownRenderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// markdownToHTML converts Markdown (CommonMark with GitHub Flavored Markdown
+// tables, task lists, strikethrough and autolinks) to HTML.
+// Raw HTML in the source is omitted. The result is marked as safe,
+// so it is not escaped again if the template is executed as HTML.
+// Usage: {{ .releaseNotes | markdown }}.
+func markdownToHTML(in string) (htmltemplate.HTML, error) {
+	var b bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(in), &b); err != nil {
+		return "", fmt.Errorf("markdown: failed to convert: %w", err)
+	}
+	return htmltemplate.HTML(b.String()), nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import "testing"
+
+func TestMdTable(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"name": "web", "replicas": 3, "note": "a|b"},
+		map[string]interface{}{"name": "api", "replicas": 10, "note": "line 1\nline 2"},
+	}
+
+	tests := []struct {
+		args     []interface{}
+		expected string
+	}{
+		{
+			[]interface{}{rows},
+			"| name | note | replicas |\n" +
+				"| --- | --- | --- |\n" +
+				"| web | a\\|b | 3 |\n" +
+				"| api | line 1<br>line 2 | 10 |",
+		},
+		{
+			[]interface{}{"name:left", "replicas:r", "missing:center", rows},
+			"| name | replicas | missing |\n" +
+				"| :--- | ---: | :---: |\n" +
+				"| web | 3 |  |\n" +
+				"| api | 10 |  |",
+		},
+	}
+
+	for _, tt := range tests {
+		actual, err := mdTable(tt.args...)
+		if err != nil {
+			t.Errorf("mdTable(%v) returned an error: %v", tt.args, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf("mdTable(%v) was incorrect, got: %q, want: %q.", tt.args, actual, tt.expected)
+		}
+	}
+
+	if _, err := mdTable("name:top", rows); err == nil {
+		t.Errorf("mdTable with unsupported alignment succeeded, but was expected to fail")
+	}
+	if _, err := mdTable([]interface{}{"not a map"}); err == nil {
+		t.Errorf("mdTable with list of strings succeeded, but was expected to fail")
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   string
+		expected string
+	}{
+		{"mdEscape", mdEscape("*bold* [link](url) | a_b"), `\*bold\* \[link\]\(url\) \| a\_b`},
+		{"mdCode", mdCode("yaml", "key: value\n"), "```yaml\nkey: value\n```"},
+		{"mdCode", mdCode("", "```go\n```"), "````\n```go\n```\n````"},
+		{"mdDetails", mdDetails("Log <full>", "text"), "<details>\n<summary>Log &lt;full&gt;</summary>\n\ntext\n\n</details>"},
+		{"mdAnchor", mdAnchor("Breaking changes!"), "#breaking-changes"},
+		{"mdAnchor", mdAnchor("v1.2.3 — Release"), "#v123--release"},
+	}
+
+	for _, tt := range tests {
+		if tt.actual != tt.expected {
+			t.Errorf("%s was incorrect, got: %q, want: %q.", tt.name, tt.actual, tt.expected)
+		}
+	}
+
+	list, err := mdList([]interface{}{"one", "two\nlines"})
+	if err != nil {
+		t.Fatalf("mdList returned an error: %v", err)
+	}
+	if expected := "- one\n- two\n  lines"; list != expected {
+		t.Errorf("mdList was incorrect, got: %q, want: %q.", list, expected)
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"# Release\n\n*new* feature", "<h1>Release</h1>\n<p><em>new</em> feature</p>\n"},
+		{
+			"| a | b |\n|---|--:|\n| 1 | 2 |",
+			"<table>\n<thead>\n<tr>\n<th>a</th>\n<th style=\"text-align:right\">b</th>\n</tr>\n</thead>\n" +
+				"<tbody>\n<tr>\n<td>1</td>\n<td style=\"text-align:right\">2</td>\n</tr>\n</tbody>\n</table>\n",
+		},
+		{"~~old~~ <script>alert(1)</script>", "<p><del>old</del> <!-- raw HTML omitted -->alert(1)<!-- raw HTML omitted --></p>\n"},
+	}
+
+	for _, tt := range tests {
+		actual, err := markdownToHTML(tt.in)
+		if err != nil {
+			t.Errorf("markdownToHTML(%q) returned an error: %v", tt.in, err)
+			continue
+		}
+		if string(actual) != tt.expected {
+			t.Errorf("markdownToHTML(%q) was incorrect, got: %q, want: %q.", tt.in, actual, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// minMaskLength is the shortest secret var value or line of multiline value that is masked:
+// masking values like "}" or "1" would hide every such character in the logs.
+const minMaskLength = 4
+
+// maskWriter receives workflow commands, it's the step log.
+var maskWriter io.Writer = os.Stdout
+
+// masked holds values already masked, so each is registered once.
+var masked = map[string]bool{}
+
+// mask registers value as secret, so GitHub replaces it with *** in logs.
+// Lines of multiline value and base64 forms are masked too.
+// Values shorter than minMaskLength are not masked.
+func mask(value string) {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	if len(strings.TrimSpace(value)) < minMaskLength {
+		return
+	}
+
+	candidates := []string{value, base64.StdEncoding.EncodeToString([]byte(value))}
+	if strings.Contains(value, "\n") {
+		for _, line := range strings.Split(value, "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) < minMaskLength {
+				continue
+			}
+			candidates = append(candidates, line, base64.StdEncoding.EncodeToString([]byte(line)))
+		}
+	}
+
+	for _, s := range candidates {
+		if masked[s] {
+			continue
+		}
+		masked[s] = true
+		if strings.Contains(s, "\n") {
+			// workflow commands are single-line, lines are masked separately
+			continue
+		}
+		fmt.Fprintf(maskWriter, "::add-mask::%s\n", s)
+	}
+}
+
+// maskVars masks values of vars at the given paths (comma or new line separated,
+// e.g. "token, db.password"); for maps and lists every value inside is masked.
+// Numbers and booleans are masked as they are written, e.g. PIN 12345678.
+func maskVars(v vars, paths string) error {
+	for _, path := range strings.FieldsFunc(paths, func(r rune) bool { return r == ',' || r == '\n' }) {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		value, ok := lookupPath(v, path)
+		if !ok {
+			return fmt.Errorf("secret var %q not found", path)
+		}
+		maskValue(value)
+	}
+	return nil
+}
+
+func maskValue(value interface{}) {
+	switch val := value.(type) {
+	case map[string]interface{}, vars:
+		m, _ := toMap(val)
+		for _, k := range sortedKeys(m) {
+			maskValue(m[k])
+		}
+	case []interface{}:
+		for _, item := range val {
+			maskValue(item)
+		}
+	case nil:
+		// empty value, nothing to hide
+	default:
+		mask(fmt.Sprint(val))
+	}
+}
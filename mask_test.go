@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestMaskVars(t *testing.T) {
+	var b bytes.Buffer
+	maskWriter = &b
+	t.Cleanup(func() {
+		maskWriter = os.Stdout
+		masked = map[string]bool{}
+	})
+
+	v := vars{
+		"token": "s3cr3t",
+		"db": map[string]interface{}{
+			"password": "hunter2",
+			"port":     5432,
+			"tls":      true,
+			"user":     "db",
+		},
+		"key":    "-----BEGIN KEY-----\nabc\nAAAABBBB\n-----END KEY-----",
+		"public": "hello",
+	}
+
+	if err := maskVars(v, "token, db\nkey,token"); err != nil {
+		t.Fatalf("maskVars() returned error: %v", err)
+	}
+
+	expected := "::add-mask::s3cr3t\n" +
+		"::add-mask::czNjcjN0\n" +
+		"::add-mask::hunter2\n" +
+		"::add-mask::aHVudGVyMg==\n" +
+		"::add-mask::5432\n" +
+		"::add-mask::NTQzMg==\n" +
+		"::add-mask::true\n" +
+		"::add-mask::dHJ1ZQ==\n" +
+		"::add-mask::LS0tLS1CRUdJTiBLRVktLS0tLQphYmMKQUFBQUJCQkIKLS0tLS1FTkQgS0VZLS0tLS0=\n" +
+		"::add-mask::-----BEGIN KEY-----\n" +
+		"::add-mask::LS0tLS1CRUdJTiBLRVktLS0tLQ==\n" +
+		"::add-mask::AAAABBBB\n" +
+		"::add-mask::QUFBQUJCQkI=\n" +
+		"::add-mask::-----END KEY-----\n" +
+		"::add-mask::LS0tLS1FTkQgS0VZLS0tLS0=\n"
+	if b.String() != expected {
+		t.Errorf("maskVars() was incorrect, got:\n%s\nwant:\n%s", b.String(), expected)
+	}
+
+	if err := maskVars(v, "missing"); err == nil {
+		t.Errorf("maskVars() expected error for missing var, got nil")
+	}
+}
+
+func TestMaskShort(t *testing.T) {
+	var b bytes.Buffer
+	maskWriter = &b
+	t.Cleanup(func() {
+		maskWriter = os.Stdout
+		masked = map[string]bool{}
+	})
+
+	// masking "1" would hide every 1 in the logs
+	for _, value := range []string{"1", " ab\n", "}\n{"} {
+		mask(value)
+	}
+	if err := maskVars(vars{"pin": 12345678, "n": 7}, "pin, n"); err != nil {
+		t.Fatalf("maskVars() returned error: %v", err)
+	}
+
+	expected := "::add-mask::12345678\n" +
+		"::add-mask::MTIzNDU2Nzg=\n"
+	if b.String() != expected {
+		t.Errorf("mask() was incorrect, got:\n%s\nwant:\n%s", b.String(), expected)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// operand is a number from vars: YAML decodes integers as int
+// and other numbers as float64, while vars input may contain numeric strings.
+type operand struct {
+	i     int
+	f     float64
+	isInt bool
+}
+
+func toOperand(in interface{}) (operand, error) {
+	switch v := in.(type) {
+	case int:
+		return operand{i: v, f: float64(v), isInt: true}, nil
+	case int64:
+		return operand{i: int(v), f: float64(v), isInt: true}, nil
+	case int8, int16, int32, uint, uint8, uint16, uint32, uint64:
+		f, _ := toFloat(v)
+		return operand{i: int(f), f: f, isInt: true}, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if i, err := strconv.Atoi(s); err == nil {
+			return operand{i: i, f: float64(i), isInt: true}, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return operand{}, fmt.Errorf("failed to parse number %q", v)
+		}
+		return operand{f: f}, nil
+	default:
+		f, err := toFloat(in)
+		if err != nil {
+			return operand{}, err
+		}
+		return operand{f: f}, nil
+	}
+}
+
+func toOperands(name string, args []interface{}) ([]operand, bool, error) {
+	if len(args) == 0 {
+		return nil, false, fmt.Errorf("%s: expected at least 1 argument", name)
+	}
+	ops := make([]operand, 0, len(args))
+	allInts := true
+	for _, arg := range args {
+		op, err := toOperand(arg)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", name, err)
+		}
+		allInts = allInts && op.isInt
+		ops = append(ops, op)
+	}
+	return ops, allInts, nil
+}
+
+// reduce applies integer or float operation to all arguments, left to right.
+// The result is int if all arguments are integers, float64 otherwise.
+func reduce(name string, args []interface{}, intOp func(a, b int) int, floatOp func(a, b float64) float64) (interface{}, error) {
+	ops, allInts, err := toOperands(name, args)
+	if err != nil {
+		return nil, err
+	}
+	if allInts {
+		result := ops[0].i
+		for _, op := range ops[1:] {
+			result = intOp(result, op.i)
+		}
+		return result, nil
+	}
+	result := ops[0].f
+	for _, op := range ops[1:] {
+		result = floatOp(result, op.f)
+	}
+	return result, nil
+}
+
+// add returns the sum of its arguments.
+// Usage: {{ add .replicas 2 }}.
+func add(args ...interface{}) (interface{}, error) {
+	return reduce("add", args,
+		func(a, b int) int { return a + b },
+		func(a, b float64) float64 { return a + b },
+	)
+}
+
+// sub subtracts b from a.
+func sub(a, b interface{}) (interface{}, error) {
+	return reduce("sub", []interface{}{a, b},
+		func(a, b int) int { return a - b },
+		func(a, b float64) float64 { return a - b },
+	)
+}
+
+// mul returns the product of its arguments.
+// Usage: {{ mul .replicas 2 }}.
+func mul(args ...interface{}) (interface{}, error) {
+	return reduce("mul", args,
+		func(a, b int) int { return a * b },
+		func(a, b float64) float64 { return a * b },
+	)
+}
+
+var errDivisionByZero = errors.New("division by zero")
+
+// div divides a by b; integer division if both are integers.
+func div(a, b interface{}) (interface{}, error) {
+	divisor, err := toOperand(b)
+	if err != nil {
+		return nil, fmt.Errorf("div: %w", err)
+	}
+	if divisor.f == 0 {
+		return nil, fmt.Errorf("div: %w", errDivisionByZero)
+	}
+	return reduce("div", []interface{}{a, b},
+		func(a, b int) int { return a / b },
+		func(a, b float64) float64 { return a / b },
+	)
+}
+
+// mod returns the remainder of a divided by b.
+func mod(a, b interface{}) (interface{}, error) {
+	divisor, err := toOperand(b)
+	if err != nil {
+		return nil, fmt.Errorf("mod: %w", err)
+	}
+	if divisor.f == 0 {
+		return nil, fmt.Errorf("mod: %w", errDivisionByZero)
+	}
+	return reduce("mod", []interface{}{a, b},
+		func(a, b int) int { return a % b },
+		math.Mod,
+	)
+}
+
+// maxOf returns the largest of its arguments.
+func maxOf(args ...interface{}) (interface{}, error) {
+	return reduce("max", args,
+		func(a, b int) int {
+			if b > a {
+				return b
+			}
+			return a
+		},
+		math.Max,
+	)
+}
+
+// minOf returns the smallest of its arguments.
+func minOf(args ...interface{}) (interface{}, error) {
+	return reduce("min", args,
+		func(a, b int) int {
+			if b < a {
+				return b
+			}
+			return a
+		},
+		math.Min,
+	)
+}
+
+func floor(in interface{}) (float64, error) {
+	f, err := toFloat(in)
+	if err != nil {
+		return 0, fmt.Errorf("floor: %w", err)
+	}
+	return math.Floor(f), nil
+}
+
+func ceil(in interface{}) (float64, error) {
+	f, err := toFloat(in)
+	if err != nil {
+		return 0, fmt.Errorf("ceil: %w", err)
+	}
+	return math.Ceil(f), nil
+}
+
+// round rounds number half away from zero, optionally to the given number of decimal places.
+// Usage: {{ .ratio | round }} or {{ round .ratio 2 }}.
+func round(in interface{}, precision ...int) (float64, error) {
+	f, err := toFloat(in)
+	if err != nil {
+		return 0, fmt.Errorf("round: %w", err)
+	}
+	if len(precision) == 0 {
+		return math.Round(f), nil
+	}
+	scale := math.Pow10(precision[0])
+	return math.Round(f*scale) / scale, nil
+}
+
+// intValue converts number or numeric string to int, truncating fractional part.
+// Usage: {{ .replicas | int }}.
+func intValue(in interface{}) (int, error) {
+	op, err := toOperand(in)
+	if err != nil {
+		return 0, fmt.Errorf("int: %w", err)
+	}
+	if op.isInt {
+		return op.i, nil
+	}
+	return int(op.f), nil
+}
+
+// floatValue converts number or numeric string to float64.
+func floatValue(in interface{}) (float64, error) {
+	f, err := toFloat(in)
+	if err != nil {
+		return 0, fmt.Errorf("float: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMath(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func() (interface{}, error)
+		expected interface{}
+	}{
+		{"add", func() (interface{}, error) { return add(1, 2, "3") }, 6},
+		{"add", func() (interface{}, error) { return add(1, 0.5) }, 1.5},
+		{"sub", func() (interface{}, error) { return sub("10", 4) }, 6},
+		{"mul", func() (interface{}, error) { return mul(3, 2) }, 6},
+		{"mul", func() (interface{}, error) { return mul("512", 1.5) }, 768.0},
+		{"div", func() (interface{}, error) { return div(7, 2) }, 3},
+		{"div", func() (interface{}, error) { return div(7.0, 2) }, 3.5},
+		{"mod", func() (interface{}, error) { return mod(7, 3) }, 1},
+		{"mod", func() (interface{}, error) { return mod(7.5, 2) }, 1.5},
+		{"max", func() (interface{}, error) { return maxOf(1, 5, int64(3)) }, 5},
+		{"min", func() (interface{}, error) { return minOf(1.5, 5, 3) }, 1.5},
+		{"floor", func() (interface{}, error) { return floor("1.7") }, 1.0},
+		{"ceil", func() (interface{}, error) { return ceil(1.2) }, 2.0},
+		{"round", func() (interface{}, error) { return round(2.5) }, 3.0},
+		{"round", func() (interface{}, error) { return round(3.14159, 2) }, 3.14},
+		{"int", func() (interface{}, error) { return intValue("42") }, 42},
+		{"int", func() (interface{}, error) { return intValue(3.9) }, 3},
+		{"float", func() (interface{}, error) { return floatValue("0.5") }, 0.5},
+	}
+
+	for _, tt := range tests {
+		actual, err := tt.fn()
+		if err != nil {
+			t.Errorf("%s returned an error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%s was incorrect, got: %v (%T), want: %v (%T).", tt.name, actual, actual, tt.expected, tt.expected)
+		}
+	}
+}
+
+func TestMathErrors(t *testing.T) {
+	if _, err := div(1, 0); !errors.Is(err, errDivisionByZero) {
+		t.Errorf("div(1, 0) expected division by zero error, got: %v", err)
+	}
+	if _, err := mod(1, "0"); !errors.Is(err, errDivisionByZero) {
+		t.Errorf("mod(1, \"0\") expected division by zero error, got: %v", err)
+	}
+	if _, err := add(1, "two"); err == nil {
+		t.Errorf("add(1, \"two\") succeeded, but was expected to fail")
+	}
+	if _, err := maxOf(); err == nil {
+		t.Errorf("max() succeeded, but was expected to fail")
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+var defaultLocale = language.English
+
+// formatNumber formats value using locale-specific grouping and decimal separators.
+// Usage: {{ .value | number }} or {{ .value | number "de-DE" }}.
+func formatNumber(args ...interface{}) string {
+	p, in, err := numberArgs("number", args)
+	if err != nil {
+		log.Print(err)
+		return lastArg(args)
+	}
+	if f, ok := in.(float64); ok {
+		// keep precision of the input instead of the default 3 fraction digits
+		return p.Sprint(number.Decimal(f, number.MaxFractionDigits(fractionDigits(f))))
+	}
+	return p.Sprint(number.Decimal(in))
+}
+
+// fractionDigits returns number of digits after the decimal point
+// in the shortest representation of f.
+func fractionDigits(f float64) int {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// formatCurrency formats value as an amount in the given ISO 4217 currency.
+// Usage: {{ .price | currency "EUR" }} or {{ .price | currency "EUR" "de-DE" }}.
+func formatCurrency(code string, args ...interface{}) string {
+	p, in, err := numberArgs("currency", args)
+	if err != nil {
+		log.Print(err)
+		return lastArg(args)
+	}
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		log.Printf("failed to parse currency %q: %v", code, err)
+		return lastArg(args)
+	}
+	return p.Sprint(currency.Symbol(unit.Amount(in)))
+}
+
+// formatPercent formats a ratio (0.25) as a percentage (25%).
+func formatPercent(args ...interface{}) string {
+	p, in, err := numberArgs("percent", args)
+	if err != nil {
+		log.Print(err)
+		return lastArg(args)
+	}
+	return p.Sprint(number.Percent(in))
+}
+
+var compactSuffixes = []string{"", "K", "M", "B", "T"}
+
+// formatCompact formats value in a short form, e.g. 1234 as 1.2K.
+func formatCompact(args ...interface{}) string {
+	p, in, err := numberArgs("compact", args)
+	if err != nil {
+		log.Print(err)
+		return lastArg(args)
+	}
+	return scaleNumber(p, floatOf(in), 1000, compactSuffixes, "")
+}
+
+var (
+	bytesIECSuffixes = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	bytesSISuffixes  = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+)
+
+// formatBytes formats a size in bytes using binary units (KiB, MiB, ...).
+func formatBytes(args ...interface{}) string {
+	p, in, err := numberArgs("bytes", args)
+	if err != nil {
+		log.Print(err)
+		return lastArg(args)
+	}
+	return scaleNumber(p, floatOf(in), 1024, bytesIECSuffixes, " ")
+}
+
+// formatBytesSI formats a size in bytes using decimal units (kB, MB, ...).
+func formatBytesSI(args ...interface{}) string {
+	p, in, err := numberArgs("bytesSI", args)
+	if err != nil {
+		log.Print(err)
+		return lastArg(args)
+	}
+	return scaleNumber(p, floatOf(in), 1000, bytesSISuffixes, " ")
+}
+
+func scaleNumber(p *message.Printer, in float64, base float64, suffixes []string, sep string) string {
+	i := 0
+	for math.Abs(in) >= base && i < len(suffixes)-1 {
+		in /= base
+		i++
+	}
+	// rounding to one fraction digit may reach the next unit, e.g. 999999 is 1000K
+	if math.Abs(math.Round(in*10)/10) >= base && i < len(suffixes)-1 {
+		in /= base
+		i++
+	}
+	if suffixes[i] == "" {
+		return p.Sprint(number.Decimal(in, number.MaxFractionDigits(1)))
+	}
+	return p.Sprint(number.Decimal(in, number.MaxFractionDigits(1))) + sep + suffixes[i]
+}
+
+// numberArgs splits template function arguments into an optional locale
+// and the value to format (always the last argument, so it can be piped).
+// Integers are returned as int64 or uint64 to be formatted exactly, other numbers as float64.
+func numberArgs(name string, args []interface{}) (*message.Printer, interface{}, error) {
+	switch len(args) {
+	case 1:
+		in, err := toDecimal(args[0])
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: failed to parse number: %w", name, err)
+		}
+		return message.NewPrinter(defaultLocale), in, nil
+	case 2:
+		locale, ok := args[0].(string)
+		if !ok {
+			return nil, 0, fmt.Errorf("%s: locale must be a string, got %T", name, args[0])
+		}
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: failed to parse locale %q: %w", name, locale, err)
+		}
+		in, err := toDecimal(args[1])
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: failed to parse number: %w", name, err)
+		}
+		return message.NewPrinter(tag), in, nil
+	default:
+		return nil, 0, fmt.Errorf("%s: expected 1 or 2 arguments, got %d", name, len(args))
+	}
+}
+
+func lastArg(args []interface{}) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return fmt.Sprint(args[len(args)-1])
+}
+
+// toDecimal converts integers and integer strings to int64 or uint64,
+// other numbers to float64.
+func toDecimal(in interface{}) (interface{}, error) {
+	switch v := in.(type) {
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint:
+		return uint64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return u, nil
+		}
+	}
+	return toFloat(in)
+}
+
+// floatOf converts value returned by toDecimal to float64.
+func floatOf(in interface{}) float64 {
+	switch v := in.(type) {
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return in.(float64)
+}
+
+// toFloat converts values produced by the YAML decoder (int, float64)
+// and numeric strings to float64.
+func toFloat(in interface{}) (float64, error) {
+	switch v := in.(type) {
+	case int:
+		return float64(v), nil
+	case int8:
+		return float64(v), nil
+	case int16:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint8:
+		return float64(v), nil
+	case uint16:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	default:
+		return 0, fmt.Errorf("unsupported type %T", in)
+	}
+}
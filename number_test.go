@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		args     []interface{}
+		expected string
+	}{
+		{[]interface{}{"1000"}, "1,000"},
+		{[]interface{}{1234567}, "1,234,567"},
+		{[]interface{}{"1234.5"}, "1,234.5"},
+		{[]interface{}{1234.5}, "1,234.5"},
+		{[]interface{}{"de-DE", 1234.5}, "1.234,5"},
+		{[]interface{}{"9007199254740993"}, "9,007,199,254,740,993"},
+		{[]interface{}{int64(1234567890123456789)}, "1,234,567,890,123,456,789"},
+		{[]interface{}{uint64(18446744073709551615)}, "18,446,744,073,709,551,615"},
+		{[]interface{}{1.23456789}, "1.23456789"},
+		{[]interface{}{"abc"}, "abc"},
+		{[]interface{}{"not-a-locale!", 1}, "1"},
+	}
+
+	for _, tt := range tests {
+		actual := formatNumber(tt.args...)
+		if actual != tt.expected {
+			t.Errorf("formatNumber(%v) was incorrect, got: %q, want: %q.", tt.args, actual, tt.expected)
+		}
+	}
+}
+
+func TestFormatCurrency(t *testing.T) {
+	tests := []struct {
+		code     string
+		args     []interface{}
+		expected string
+	}{
+		{"EUR", []interface{}{1234.5}, "€ 1,234.50"},
+		{"EUR", []interface{}{"de-DE", "1234.5"}, "€ 1.234,50"},
+		{"XYZ", []interface{}{10}, "10"},
+	}
+
+	for _, tt := range tests {
+		actual := formatCurrency(tt.code, tt.args...)
+		if actual != tt.expected {
+			t.Errorf("formatCurrency(%q, %v) was incorrect, got: %q, want: %q.", tt.code, tt.args, actual, tt.expected)
+		}
+	}
+}
+
+func TestFormatScaled(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(...interface{}) string
+		args     []interface{}
+		expected string
+	}{
+		{"percent", formatPercent, []interface{}{0.25}, "25%"},
+		{"percent", formatPercent, []interface{}{"de-DE", 0.25}, "25\u00a0%"},
+		{"compact", formatCompact, []interface{}{999}, "999"},
+		{"compact", formatCompact, []interface{}{1234}, "1.2K"},
+		{"compact", formatCompact, []interface{}{"2500000"}, "2.5M"},
+		{"bytes", formatBytes, []interface{}{512}, "512 B"},
+		{"bytes", formatBytes, []interface{}{1536}, "1.5 KiB"},
+		{"compact", formatCompact, []interface{}{999999}, "1M"},
+		{"bytes", formatBytes, []interface{}{1048575}, "1 MiB"},
+		{"bytesSI", formatBytesSI, []interface{}{1500000}, "1.5 MB"},
+	}
+
+	for _, tt := range tests {
+		actual := tt.fn(tt.args...)
+		if actual != tt.expected {
+			t.Errorf("%s(%v) was incorrect, got: %q, want: %q.", tt.name, tt.args, actual, tt.expected)
+		}
+	}
+}
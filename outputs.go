@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// namedOutput is a named step output, in addition to `result`.
+type namedOutput struct {
+	name  string
+	value string
+}
+
+// stepOutputs are named outputs set while rendering, in order of setting.
+var stepOutputs []namedOutput
+
+var outputNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// setOutput sets named step output, available to later steps
+// as `steps.<id>.outputs.<name>`. Values other than strings are JSON-encoded.
+// It renders nothing.
+// Usage: {{ setOutput "image" .image }}.
+func setOutput(name string, value interface{}) (string, error) {
+	if !outputNameRe.MatchString(name) {
+		return "", fmt.Errorf("setOutput: invalid output name %q", name)
+	}
+	if reservedOutputs[name] {
+		return "", fmt.Errorf("setOutput: output name %q is reserved", name)
+	}
+
+	s, err := outputValue(value)
+	if err != nil {
+		return "", fmt.Errorf("setOutput: failed to marshal %q: %w", name, err)
+	}
+	setStepOutput(name, s)
+	return "", nil
+}
+
+// reservedOutputs are set by the action itself, templates can't override them.
+var reservedOutputs = map[string]bool{
+	"result":  true,
+	"sha256":  true,
+	"changed": true,
+}
+
+// setStepOutput sets named step output, replacing previous value.
+func setStepOutput(name, value string) {
+	for i := range stepOutputs {
+		if stepOutputs[i].name == name {
+			stepOutputs[i].value = value
+			return
+		}
+	}
+	stepOutputs = append(stepOutputs, namedOutput{name: name, value: value})
+}
+
+// setResultOutputs treats rendered YAML map as named step outputs.
+func setResultOutputs(result string) error {
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(result), &m); err != nil {
+		return fmt.Errorf("failed to parse result as YAML map: %w", err)
+	}
+	for _, k := range sortedKeys(m) {
+		if _, err := setOutput(k, m[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputValue returns string as is, other values JSON-encoded.
+func outputValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// exportEnv treats rendered YAML map as environment variables
+// and appends them to GITHUB_ENV, so they are available to later steps.
+// Multiline values use random delimiter, so they can't set other variables.
+func exportEnv(result string) error {
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(result), &m); err != nil {
+		return fmt.Errorf("failed to parse result as YAML map: %w", err)
+	}
+
+	var b strings.Builder
+	for _, k := range sortedKeys(m) {
+		if !envNameRe.MatchString(k) {
+			return fmt.Errorf("invalid environment variable name %q", k)
+		}
+		value, err := outputValue(m[k])
+		if err != nil {
+			return fmt.Errorf("failed to marshal %q: %w", k, err)
+		}
+		if value == "" {
+			// formatOutput skips empty values, but empty env var still overrides previous one
+			b.WriteString(k + "=\n")
+			continue
+		}
+		line, err := formatOutput(k, value)
+		if err != nil {
+			return err
+		}
+		b.WriteString(line + "\n")
+	}
+	return appendGitHubFile("GITHUB_ENV", b.String())
+}
+
+// writeSummary appends rendered Markdown to the job summary shown on the run page.
+func writeSummary(result string) error {
+	if result == "" {
+		return nil
+	}
+	if !strings.HasSuffix(result, "\n") {
+		result += "\n"
+	}
+	return appendGitHubFile("GITHUB_STEP_SUMMARY", result)
+}
+
+// appendGitHubFile appends content to the file named by the environment variable,
+// e.g. GITHUB_OUTPUT.
+func appendGitHubFile(envName, content string) error {
+	if content == "" {
+		return nil
+	}
+
+	path := os.Getenv(envName)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to open %s file %q: %v. "+
+				"If you are using self-hosted runners "+
+				"make sure they are updated to version 2.297.0 or greater",
+			envName,
+			path,
+			err,
+		)
+	}
+	defer f.Close()
+
+	if _, err = f.WriteString(content); err != nil {
+		return fmt.Errorf("failed to write to %s file %q: %w", envName, path, err)
+	}
+
+	return nil
+}
+
+// Modes of `result` output.
+const (
+	outputFull   = "full"
+	outputNone   = "none"
+	outputPath   = "path"
+	outputSHA256 = "sha256"
+)
+
+func checkOutputMode(mode string) error {
+	switch mode {
+	case outputFull, outputNone, outputPath, outputSHA256:
+		return nil
+	}
+	return fmt.Errorf("unsupported output mode %q, expected none, full, path or sha256", mode)
+}
+
+// resultOutput returns value of `result` output for rendered output:
+// the content itself, nothing, path to the result file or SHA-256 checksum of the content.
+// Content larger than maxSize (if positive) falls back to the path
+// with checksum in `sha256` output, so GITHUB_OUTPUT stays within its size limit.
+func resultOutput(mode string, maxSize int, r rendered) (string, error) {
+	switch mode {
+	case outputNone:
+		return "", nil
+	case outputPath:
+		if r.path == "" {
+			return "", fmt.Errorf("output mode %q requires result_path", mode)
+		}
+		return r.path, nil
+	case outputSHA256:
+		return hashSum("sha256")(r.output), nil
+	}
+
+	if maxSize <= 0 || len(r.output) <= maxSize {
+		return r.output, nil
+	}
+
+	sum := hashSum("sha256")(r.output)
+	setStepOutput("sha256", sum)
+	if r.path == "" {
+		fmt.Printf("::warning::rendered output is larger than %d bytes, result output is its SHA-256 checksum\n", maxSize)
+		return sum, nil
+	}
+	fmt.Printf("::warning::rendered output is larger than %d bytes, result output is path to the file\n", maxSize)
+	return r.path, nil
+}
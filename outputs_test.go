@@ -0,0 +1,212 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetOutput(t *testing.T) {
+	t.Cleanup(func() { stepOutputs = nil })
+
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"image", "ghcr.io/acme/app:v1"},
+		{"replicas", 3},
+		{"tags", []interface{}{"v1", "latest"}},
+		{"empty", nil},
+		{"image", "ghcr.io/acme/app:v2"},
+	}
+	for _, tt := range tests {
+		out, err := setOutput(tt.name, tt.value)
+		if err != nil || out != "" {
+			t.Errorf("setOutput(%q, %v) was incorrect, got: %q (%v), want: \"\".", tt.name, tt.value, out, err)
+		}
+	}
+
+	expected := []namedOutput{
+		{"image", "ghcr.io/acme/app:v2"},
+		{"replicas", "3"},
+		{"tags", `["v1","latest"]`},
+		{"empty", ""},
+	}
+	if !reflect.DeepEqual(stepOutputs, expected) {
+		t.Errorf("stepOutputs were incorrect, got: %+v, want: %+v.", stepOutputs, expected)
+	}
+
+	for _, name := range []string{"result", "sha256", "changed", "", "a=b", "a\nb", "1st"} {
+		if _, err := setOutput(name, "x"); err == nil {
+			t.Errorf("setOutput(%q) expected error, got nil", name)
+		}
+	}
+}
+
+func TestSetResultOutputs(t *testing.T) {
+	t.Cleanup(func() { stepOutputs = nil })
+
+	if err := setResultOutputs("tag: v1\nimage: app\nports: [80, 443]\n"); err != nil {
+		t.Fatalf("setResultOutputs() returned error: %v", err)
+	}
+	expected := []namedOutput{
+		{"image", "app"},
+		{"ports", "[80,443]"},
+		{"tag", "v1"},
+	}
+	if !reflect.DeepEqual(stepOutputs, expected) {
+		t.Errorf("stepOutputs were incorrect, got: %+v, want: %+v.", stepOutputs, expected)
+	}
+
+	if err := setResultOutputs("- not a map\n"); err == nil {
+		t.Errorf("setResultOutputs() expected error for a list, got nil")
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	t.Cleanup(func() { stepOutputs = nil })
+	stubDelimiter(t, "EOF")
+
+	path := filepath.Join(t.TempDir(), "output")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_OUTPUT", path)
+
+	if _, err := executeTemplate("test", `{{ setOutput "image" .image }}{{ setOutput "notes" "a\nb" }}done`, templateFuncs("test"), vars{"image": "app:v1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeOutput("done"); err != nil {
+		t.Fatalf("writeOutput() returned error: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "result=done\nimage=app:v1\nnotes<<EOF\na\nb\nEOF\n"
+	if string(b) != expected {
+		t.Errorf("writeOutput() was incorrect, got: %q, want: %q.", b, expected)
+	}
+}
+
+func TestExportEnv(t *testing.T) {
+	stubDelimiter(t, "EOF")
+
+	path := filepath.Join(t.TempDir(), "env")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_ENV", path)
+
+	if err := exportEnv("IMAGE: app:v1\nNOTES: |\n  a\n  b\nREPLICAS: 3\nEMPTY: \"\"\n"); err != nil {
+		t.Fatalf("exportEnv() returned error: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "EMPTY=\nIMAGE=app:v1\nNOTES<<EOF\na\nb\n\nEOF\nREPLICAS=3\n"
+	if string(b) != expected {
+		t.Errorf("exportEnv() was incorrect, got: %q, want: %q.", b, expected)
+	}
+
+	for _, in := range []string{"- list\n", "MY-VAR: x\n"} {
+		if err := exportEnv(in); err == nil {
+			t.Errorf("exportEnv(%q) expected error, got nil", in)
+		}
+	}
+}
+
+func TestExportEnvDelimiter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "env")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_ENV", path)
+
+	// value can't end the heredoc early and set other variables
+	if err := exportEnv("NOTES: \"a\\nOUTPUT\\nLD_PRELOAD=x.so\"\n"); err != nil {
+		t.Fatalf("exportEnv() returned error: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if len(lines) != 5 || lines[0] != "NOTES<<"+lines[4] || lines[4] == "OUTPUT" {
+		t.Errorf("exportEnv() was incorrect, got: %q", b)
+	}
+
+	stubDelimiter(t, "EOF")
+	if err := exportEnv("NOTES: \"a\\nEOF\\nLD_PRELOAD=x.so\"\n"); err == nil {
+		t.Errorf("exportEnv() of value with delimiter expected error, got nil")
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary")
+	if err := os.WriteFile(path, []byte("# Previous step\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+	if err := writeSummary("## Deploy\n\n| app | v1 |"); err != nil {
+		t.Fatalf("writeSummary() returned error: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# Previous step\n## Deploy\n\n| app | v1 |\n"
+	if string(b) != expected {
+		t.Errorf("writeSummary() was incorrect, got: %q, want: %q.", b, expected)
+	}
+}
+
+func TestResultOutput(t *testing.T) {
+	t.Cleanup(func() { stepOutputs = nil })
+
+	const sum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" // sha256("hello")
+	tests := []struct {
+		mode            string
+		maxSize         int
+		r               rendered
+		expected        string
+		expectedOutputs []namedOutput
+	}{
+		{outputFull, 0, rendered{"hello", "out.txt"}, "hello", nil},
+		{outputFull, 5, rendered{"hello", "out.txt"}, "hello", nil},
+		{outputNone, 0, rendered{"hello", "out.txt"}, "", nil},
+		{outputPath, 0, rendered{"hello", "out.txt"}, "out.txt", nil},
+		{outputSHA256, 0, rendered{"hello", ""}, sum, nil},
+		{outputFull, 4, rendered{"hello", "out.txt"}, "out.txt", []namedOutput{{"sha256", sum}}},
+		{outputFull, 4, rendered{"hello", ""}, sum, []namedOutput{{"sha256", sum}}},
+	}
+
+	for _, tt := range tests {
+		stepOutputs = nil
+		actual, err := resultOutput(tt.mode, tt.maxSize, tt.r)
+		if err != nil {
+			t.Errorf("resultOutput(%q, %d) returned error: %v", tt.mode, tt.maxSize, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf("resultOutput(%q, %d) was incorrect, got: %q, want: %q.", tt.mode, tt.maxSize, actual, tt.expected)
+		}
+		if !reflect.DeepEqual(stepOutputs, tt.expectedOutputs) {
+			t.Errorf("resultOutput(%q, %d) outputs were incorrect, got: %+v, want: %+v.", tt.mode, tt.maxSize, stepOutputs, tt.expectedOutputs)
+		}
+	}
+
+	if _, err := resultOutput(outputPath, 0, rendered{"hello", ""}); err == nil {
+		t.Errorf("resultOutput(path) without result path expected error, got nil")
+	}
+	if err := checkOutputMode("content"); err == nil {
+		t.Errorf("checkOutputMode(content) expected error, got nil")
+	}
+}
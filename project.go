@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// project is a config file (e.g. .render-template.yml) describing many render jobs:
+//
+//	vars:
+//	  registry: ghcr.io/acme
+//	jobs:
+//	  - name: deployment
+//	    template: k8s/deployment.yml
+//	    vars_path: [vars/common.yml, vars/production.yml]
+//	    vars:
+//	      replicas: 3
+//	    result_path: out/deployment.yml
+//	    format: yaml
+//	    mode: "0644"
+//	  - name: services
+//	    template: k8s/service.yml
+//	    foreach: .services
+//	    result_path: "out/{{ .item.name }}.yml"
+type project struct {
+	Vars vars  `yaml:"vars"`
+	Jobs []job `yaml:"jobs"`
+}
+
+// job describes a single render: template, its vars and where to write the result.
+type job struct {
+	Name       string     `yaml:"name"`
+	Template   string     `yaml:"template"`
+	Vars       vars       `yaml:"vars"`
+	VarsPath   stringList `yaml:"vars_path"`
+	ResultPath string     `yaml:"result_path"`
+	Format     string     `yaml:"format"`
+	Mode       string     `yaml:"mode"`
+	Foreach    string     `yaml:"foreach"`
+
+	dir string // directory of the config file, result paths are relative to it
+}
+
+// stringList is a list of strings in YAML that may also be written as a single string.
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = stringList{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// loadProject reads project config file.
+// Paths in jobs are resolved relative to the config file directory.
+func loadProject(path string) (*project, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+
+	var p project
+	d := yaml.NewDecoder(bytes.NewReader(b))
+	d.KnownFields(true)
+	if err := d.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %q: %w", path, err)
+	}
+	if len(p.Jobs) == 0 {
+		return nil, fmt.Errorf("config file %q has no jobs", path)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range p.Jobs {
+		j := &p.Jobs[i]
+		if j.Name == "" {
+			j.Name = j.Template
+		}
+		if j.Template == "" {
+			return nil, fmt.Errorf("job %q: template is required", j.Name)
+		}
+		j.Template = resolvePath(dir, j.Template)
+		// result_path may be a template, it's resolved after rendering
+		j.dir = dir
+		for k := range j.VarsPath {
+			j.VarsPath[k] = resolvePath(dir, j.VarsPath[k])
+		}
+	}
+	return &p, nil
+}
+
+func resolvePath(dir, path string) string {
+	if dir == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// runProject renders all jobs from the config file with shared vars
+// and writes JSON list of result paths to `result` output.
+func runProject(c config) error {
+	p, err := loadProject(c.Config)
+	if err != nil {
+		return err
+	}
+
+	// `vars` input takes precedence over vars from the config file
+	global := mergeVars(mergeVars(vars{}, c.Vars), p.Vars)
+
+	paths := []string{}
+	changed := false
+	for _, j := range p.Jobs {
+		written, jobChanged, err := j.run(c, global)
+		if err != nil {
+			return fmt.Errorf("job %q: %w", j.Name, err)
+		}
+		paths = append(paths, written...)
+		changed = changed || jobChanged
+	}
+
+	return writePathsOutput(c, paths, changed)
+}
+
+// writePathsOutput writes JSON list of result paths to `result` output,
+// unless output mode is none, and whether any of the files changed to `changed` output.
+// List larger than output_max_size falls back to its SHA-256 checksum.
+func writePathsOutput(c config, paths []string, changed bool) error {
+	setStepOutput("changed", strconv.FormatBool(changed))
+	b, err := json.Marshal(paths)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result paths: %w", err)
+	}
+	result, err := resultOutput(c.Output, c.OutputMaxSize, rendered{output: string(b)})
+	if err != nil {
+		return err
+	}
+	return writeOutput(result)
+}
+
+// rendered is a result of rendering job template once.
+type rendered struct {
+	output string
+	path   string // may be empty if neither result_path nor front matter output is set
+}
+
+// run renders job and writes the result files, returning their paths
+// and whether any of them changed.
+func (j job) run(c config, global vars) ([]string, bool, error) {
+	mode, keepMode, err := j.fileMode(c)
+	if err != nil {
+		return nil, false, err
+	}
+
+	results, err := j.render(c, global)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if c.Split {
+		var files []rendered
+		for _, r := range results {
+			split, err := r.split()
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to split output: %w", err)
+			}
+			files = append(files, split...)
+		}
+		results = files
+	}
+
+	paths := make([]string, 0, len(results))
+	changed := false
+	for _, r := range results {
+		if r.path == "" {
+			return nil, false, fmt.Errorf("result_path is required")
+		}
+		fileChanged, err := writeFile(r.path, []byte(r.output), mode, keepMode)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to write file %q: %w", r.path, err)
+		}
+		paths = append(paths, r.path)
+		changed = changed || fileChanged
+	}
+	return paths, changed, nil
+}
+
+// render loads job vars and renders its template,
+// once per element of the foreach list if it is set.
+// Vars precedence: job vars, job vars files (later files override earlier ones),
+// global vars, template front matter defaults.
+// With mask_output, rendered outputs are masked in logs.
+func (j job) render(c config, global vars) ([]rendered, error) {
+	results, err := j.renderAll(c, global)
+	if err != nil {
+		return nil, err
+	}
+	if c.MaskOutput {
+		for _, r := range results {
+			mask(r.output)
+		}
+	}
+	return results, nil
+}
+
+// renderAll renders job template once or for each foreach item.
+func (j job) renderAll(c config, global vars) ([]rendered, error) {
+	v, err := j.loadVars(c, global)
+	if err != nil {
+		return nil, err
+	}
+
+	if j.Foreach == "" {
+		r, err := j.renderOnce(v)
+		if err != nil {
+			return nil, err
+		}
+		if j.ResultPath != "" {
+			r.path = j.ResultPath
+		}
+		r.path = resolvePath(j.dir, r.path)
+		return []rendered{r}, nil
+	}
+
+	value, ok := lookupPath(v, j.Foreach)
+	if !ok {
+		return nil, fmt.Errorf("foreach: var %q not found", j.Foreach)
+	}
+	items, err := toList(value)
+	if err != nil {
+		return nil, fmt.Errorf("foreach: var %q: %w", j.Foreach, err)
+	}
+
+	results := make([]rendered, 0, len(items))
+	seen := map[string]int{}
+	for i, item := range items {
+		// copy vars, so each item is rendered with its own `.item`
+		itemVars := mergeVars(vars{"item": item}, v)
+
+		r, err := j.renderOnce(itemVars)
+		if err != nil {
+			return nil, fmt.Errorf("foreach item %d: %w", i, err)
+		}
+		if j.ResultPath != "" {
+			r.path, err = executeTemplate("result_path", j.ResultPath, templateFuncs(j.Template), itemVars)
+			if err != nil {
+				return nil, fmt.Errorf("foreach item %d: failed to render result path: %w", i, err)
+			}
+		}
+		if r.path == "" {
+			return nil, fmt.Errorf("foreach item %d: result_path is required", i)
+		}
+		r.path = resolvePath(j.dir, r.path)
+		if prev, ok := seen[r.path]; ok {
+			return nil, fmt.Errorf("foreach items %d and %d have the same result path %q", prev, i, r.path)
+		}
+		seen[r.path] = i
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// loadVars merges job vars with its vars files and global vars.
+func (j job) loadVars(c config, global vars) (vars, error) {
+	v := mergeVars(vars{}, j.Vars)
+	for i := len(j.VarsPath) - 1; i >= 0; i-- {
+		fileVars, err := readVarsFile(j.VarsPath[i], c.VarsPathTemplate, mergeVars(mergeVars(vars{}, v), global))
+		if err != nil {
+			return nil, err
+		}
+		v = mergeVars(v, fileVars)
+	}
+	v = mergeVars(v, global)
+
+	if err := maskVars(v, c.SecretVars); err != nil {
+		return nil, err
+	}
+
+	if c.InterpolateVars {
+		// nested maps are shared with global vars used by other jobs
+		v = copyVars(v)
+		if err := interpolateVars(v, j.Template); err != nil {
+			return nil, fmt.Errorf("failed to interpolate vars: %w", err)
+		}
+		// secret vars may be rendered from other vars
+		if err := maskVars(v, c.SecretVars); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// renderOnce renders job template with vars;
+// result path is taken from the template front matter.
+func (j job) renderOnce(v vars) (rendered, error) {
+	output, fm, err := renderTemplate(j.Template, v)
+	if err != nil {
+		return rendered{}, fmt.Errorf("failed to render template: %w", err)
+	}
+	if j.Format != "" {
+		if err := (&frontMatter{Format: j.Format}).checkFormat(output); err != nil {
+			return rendered{}, fmt.Errorf("failed to render template: %w", err)
+		}
+	}
+	return rendered{output: output, path: fm.Output}, nil
+}
+
+// lookupPath returns value at dot-separated path in vars, e.g. ".services" or "app.ports".
+func lookupPath(v vars, path string) (interface{}, bool) {
+	var current interface{} = v
+	for _, key := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		m, err := toMap(current)
+		if err != nil {
+			return nil, false
+		}
+		var ok bool
+		if current, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// fileMode parses octal file mode of the result files,
+// falling back to file_mode input and 0644 for new files.
+// It reports whether existing files keep their mode:
+// when no mode is set or with preserve_file_mode.
+func (j job) fileMode(c config) (os.FileMode, bool, error) {
+	mode := j.Mode
+	if mode == "" {
+		mode = c.FileMode
+	}
+	if mode == "" {
+		return 0o644, true, nil
+	}
+	m, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || m > 0o777 {
+		return 0, false, fmt.Errorf("failed to parse file mode %q, expected octal permissions like 0644", mode)
+	}
+	return os.FileMode(m), c.PreserveFileMode, nil
+}
+
+// readVarsFile reads and parses vars file, optionally rendering it
+// as a template with data first.
+func readVarsFile(path string, asTemplate bool, data vars) (vars, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vars file %q: %w", path, err)
+	}
+	if asTemplate {
+		b, err = renderVarsFile(path, b, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render vars file %q: %w", path, err)
+		}
+	}
+	v, err := decodeVars(path, b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vars file %q: %w", path, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadProject(t *testing.T) {
+	p, err := loadProject("testdata/project/.render-template.yml")
+	if err != nil {
+		t.Fatalf("loadProject() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		resultPath   string
+		expected     string
+		expectedMode os.FileMode
+		expectedKeep bool
+	}{
+		{
+			"app",
+			"testdata/project/out/app.yml",
+			"image: ghcr.io/acme/web\nenv: production\nreplicas: 3\n",
+			0o600,
+			false,
+		},
+		{
+			"app.yml",
+			"testdata/project/out/worker.yml",
+			"image: ghcr.io/acme/web\nenv: common\nreplicas: 1\n",
+			0o644,
+			true,
+		},
+	}
+
+	if len(p.Jobs) != len(tests) {
+		t.Fatalf("loadProject() returned %d jobs, want %d", len(p.Jobs), len(tests))
+	}
+
+	global := mergeVars(vars{"registry": "ghcr.io/acme"}, p.Vars)
+	for i, tt := range tests {
+		j := p.Jobs[i]
+		if j.Name != tt.name {
+			t.Errorf("job %d name was incorrect, got: %q, want: %q.", i, j.Name, tt.name)
+		}
+		results, err := j.render(config{}, global)
+		if err != nil {
+			t.Errorf("job %q render returned error: %v", j.Name, err)
+			continue
+		}
+		if results[0].output != tt.expected {
+			t.Errorf("job %q output was incorrect, got: %q, want: %q.", j.Name, results[0].output, tt.expected)
+		}
+		if results[0].path != filepath.FromSlash(tt.resultPath) {
+			t.Errorf("job %q result path was incorrect, got: %q, want: %q.", j.Name, results[0].path, tt.resultPath)
+		}
+		mode, keep, err := j.fileMode(config{})
+		if err != nil || mode != tt.expectedMode || keep != tt.expectedKeep {
+			t.Errorf("job %q mode was incorrect, got: %v, %v (%v), want: %v, %v.", j.Name, mode, keep, err, tt.expectedMode, tt.expectedKeep)
+		}
+	}
+}
+
+func TestRunProject(t *testing.T) {
+	t.Cleanup(func() { stepOutputs = nil })
+	dir := t.TempDir()
+	template, err := filepath.Abs("testdata/project/app.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig := func(content string) string {
+		path := filepath.Join(dir, ".render-template.yml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	output := filepath.Join(dir, "output")
+	if err := os.WriteFile(output, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_OUTPUT", output)
+
+	c := config{
+		Vars: vars{"registry": "docker.io"},
+		Config: writeConfig(`
+vars:
+  registry: ghcr.io/acme
+  name: api
+  replicas: 2
+  env: dev
+jobs:
+  - template: ` + template + `
+    result_path: api.yml
+`),
+	}
+	if err := runProject(c); err != nil {
+		t.Fatalf("runProject() returned error: %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "api.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "image: docker.io/api\nenv: dev\nreplicas: 2\n"; string(b) != expected {
+		t.Errorf("runProject() result was incorrect, got: %q, want: %q.", b, expected)
+	}
+
+	b, err = os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `result=["` + filepath.Join(dir, "api.yml") + `"]` + "\nchanged=true\n"; string(b) != expected {
+		t.Errorf("runProject() output was incorrect, got: %q, want: %q.", b, expected)
+	}
+
+	c.Config = writeConfig(`
+jobs:
+  - name: broken
+    template: ` + template + `
+    result_path: broken.yml
+`)
+	err = runProject(c)
+	if err == nil || !strings.HasPrefix(err.Error(), `job "broken": failed to render template:`) {
+		t.Errorf("runProject() error was incorrect, got: %v", err)
+	}
+}
+
+func TestJobRenderForeach(t *testing.T) {
+	v := vars{
+		"registry": "ghcr.io/acme",
+		"app": map[string]interface{}{
+			"services": []interface{}{
+				map[string]interface{}{"name": "web", "replicas": 2},
+				map[string]interface{}{"name": "worker", "replicas": 1},
+			},
+		},
+	}
+
+	j := job{
+		Template:   "testdata/foreach.yml",
+		Foreach:    ".app.services",
+		ResultPath: "out/{{ .item.name }}.yml",
+	}
+	results, err := j.render(config{}, v)
+	if err != nil {
+		t.Fatalf("render() returned error: %v", err)
+	}
+
+	expected := []rendered{
+		{"name: web\nimage: ghcr.io/acme/web\nreplicas: 2\n", "out/web.yml"},
+		{"name: worker\nimage: ghcr.io/acme/worker\nreplicas: 1\n", "out/worker.yml"},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("render() was incorrect, got: %+v, want: %+v.", results, expected)
+	}
+	if _, ok := v["item"]; ok {
+		t.Errorf("render() leaked item to vars: %v", v)
+	}
+}
+
+func TestJobRenderForeachErrors(t *testing.T) {
+	v := vars{
+		"registry": "ghcr.io/acme",
+		"services": []interface{}{
+			map[string]interface{}{"name": "web", "replicas": 2},
+			map[string]interface{}{"name": "web", "replicas": 1},
+		},
+	}
+
+	tests := []struct {
+		j        job
+		expected string
+	}{
+		{
+			job{Template: "testdata/foreach.yml", Foreach: ".missing", ResultPath: "out.yml"},
+			`foreach: var ".missing" not found`,
+		},
+		{
+			job{Template: "testdata/foreach.yml", Foreach: ".registry", ResultPath: "out.yml"},
+			`foreach: var ".registry": expected a list, got string`,
+		},
+		{
+			job{Template: "testdata/foreach.yml", Foreach: ".services", ResultPath: "out/{{ .item.name }}.yml"},
+			`foreach items 0 and 1 have the same result path "out/web.yml"`,
+		},
+		{
+			job{Template: "testdata/foreach.yml", Foreach: ".services"},
+			`foreach item 0: result_path is required`,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := tt.j.render(config{}, v)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("render() error was incorrect, got: %v, want: %s.", err, tt.expected)
+		}
+	}
+}
+
+func TestJobLoadVarsInterpolateShared(t *testing.T) {
+	global := vars{
+		"image": map[string]interface{}{"name": "ghcr.io/acme/{{ .app }}"},
+	}
+	c := config{InterpolateVars: true}
+
+	for _, app := range []string{"web", "worker"} {
+		j := job{Template: "testdata/template.txt", Vars: vars{"app": app}}
+		v, err := j.loadVars(c, global)
+		if err != nil {
+			t.Fatalf("loadVars() returned error: %v", err)
+		}
+		expected := "ghcr.io/acme/" + app
+		if actual := v["image"].(map[string]interface{})["name"]; actual != expected {
+			t.Errorf("loadVars() for %q was incorrect, got: %v, want: %v.", app, actual, expected)
+		}
+	}
+
+	if actual := global["image"].(map[string]interface{})["name"]; actual != "ghcr.io/acme/{{ .app }}" {
+		t.Errorf("loadVars() changed global vars: %v", actual)
+	}
+}
+
+func TestRunProjectResultPaths(t *testing.T) {
+	t.Cleanup(func() { stepOutputs = nil })
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+	if err := os.WriteFile(output, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_OUTPUT", output)
+
+	frontMatter, err := filepath.Abs("testdata/frontmatter.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	template, err := filepath.Abs("testdata/template.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(dir, "project", ".render-template.yml")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(configPath, []byte(`
+vars:
+  app: web
+  name: world
+  apps: [a]
+jobs:
+  - template: `+frontMatter+`
+  - template: `+template+`
+    foreach: .apps
+    result_path: 'sub/{{ "x/../" }}{{ .item }}.txt'
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runProject(config{Config: configPath}); err != nil {
+		t.Fatalf("runProject() returned error: %v", err)
+	}
+	for _, path := range []string{"project/out/web.yml", "project/sub/a.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, path)); err != nil {
+			t.Errorf("runProject() didn't write %q: %v", path, err)
+		}
+	}
+}
+
+func TestWritePathsOutput(t *testing.T) {
+	t.Cleanup(func() { stepOutputs = nil })
+	output := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_OUTPUT", output)
+
+	paths := []string{"out/web.yml", "out/worker.yml"}
+	sum := hashSum("sha256")(`["out/web.yml","out/worker.yml"]`)
+	tests := []struct {
+		c        config
+		expected string
+	}{
+		{config{Output: outputFull}, "result=[\"out/web.yml\",\"out/worker.yml\"]\nchanged=true\n"},
+		{config{Output: outputNone}, "changed=true\n"},
+		{config{Output: outputFull, OutputMaxSize: 10}, "result=" + sum + "\nchanged=true\nsha256=" + sum + "\n"},
+	}
+	for _, tt := range tests {
+		stepOutputs = nil
+		if err := os.WriteFile(output, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := writePathsOutput(tt.c, paths, true); err != nil {
+			t.Fatalf("writePathsOutput() returned error: %v", err)
+		}
+		b, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.expected {
+			t.Errorf("writePathsOutput(%+v) was incorrect, got: %q, want: %q.", tt.c, b, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+var (
+	regexCacheMu sync.Mutex
+	regexCache   = map[string]*regexp.Regexp{}
+)
+
+// compileRegex returns compiled pattern, reusing it across template calls.
+func compileRegex(name, pattern string) (*regexp.Regexp, error) {
+	regexCacheMu.Lock()
+	defer regexCacheMu.Unlock()
+
+	if re, ok := regexCache[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to compile pattern %q: %w", name, pattern, err)
+	}
+	regexCache[pattern] = re
+	return re, nil
+}
+
+// regexMatch reports whether string contains any match of pattern.
+// Usage: {{ if .branch | regexMatch "^release/" }}.
+func regexMatch(pattern, in string) (bool, error) {
+	re, err := compileRegex("regexMatch", pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(in), nil
+}
+
+// regexFind returns the first match of pattern in string.
+func regexFind(pattern, in string) (string, error) {
+	re, err := compileRegex("regexFind", pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.FindString(in), nil
+}
+
+// regexFindAll returns all matches of pattern in string.
+func regexFindAll(pattern, in string) ([]string, error) {
+	re, err := compileRegex("regexFindAll", pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.FindAllString(in, -1), nil
+}
+
+// regexReplaceAll replaces all matches of pattern with replacement,
+// expanding $1 or ${name} to the captured groups.
+// Usage: {{ .branch | regexReplaceAll "[^a-z0-9]+" "-" }}.
+func regexReplaceAll(pattern, replacement, in string) (string, error) {
+	re, err := compileRegex("regexReplaceAll", pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(in, replacement), nil
+}
+
+// regexSplit splits string into substrings separated by pattern matches.
+func regexSplit(pattern, in string) ([]string, error) {
+	re, err := compileRegex("regexSplit", pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.Split(in, -1), nil
+}
+
+// regexCaptures returns named groups of the first match as a map.
+// Usage: {{ $v := .tag | regexCaptures `^v(?P<version>[0-9.]+)(-(?P<pre>.+))?$` }}{{ $v.version }}.
+func regexCaptures(pattern, in string) (map[string]string, error) {
+	re, err := compileRegex("regexCaptures", pattern)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]string{}
+	match := re.FindStringSubmatch(in)
+	if match == nil {
+		return result, nil
+	}
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			result[name] = match[i]
+		}
+	}
+	return result, nil
+}
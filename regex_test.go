@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegex(t *testing.T) {
+	if ok, err := regexMatch(`^v\d+`, "v1.2.3"); !ok || err != nil {
+		t.Errorf("regexMatch(%q, %q) expected true, got: %v (%v)", `^v\d+`, "v1.2.3", ok, err)
+	}
+
+	if actual, _ := regexFind(`\d+\.\d+\.\d+`, "v1.2.3-rc1"); actual != "1.2.3" {
+		t.Errorf("regexFind was incorrect, got: %q, want: %q.", actual, "1.2.3")
+	}
+
+	if actual, _ := regexFindAll(`\d+`, "v1.2.3-rc1"); !reflect.DeepEqual(actual, []string{"1", "2", "3", "1"}) {
+		t.Errorf("regexFindAll was incorrect, got: %q", actual)
+	}
+
+	tests := []struct {
+		pattern, replacement, in string
+		expected                 string
+	}{
+		{`[^a-z0-9]+`, "-", "feature/JIRA-12_fix", "feature-12-fix"},
+		{`^v(\d+)\.(\d+).*$`, "$1.$2", "v1.2.3", "1.2"},
+		{`(?P<major>\d+)`, "${major}x", "v1", "v1x"},
+	}
+	for _, tt := range tests {
+		actual, err := regexReplaceAll(tt.pattern, tt.replacement, tt.in)
+		if err != nil || actual != tt.expected {
+			t.Errorf("regexReplaceAll(%q, %q, %q) was incorrect, got: %q (%v), want: %q.", tt.pattern, tt.replacement, tt.in, actual, err, tt.expected)
+		}
+	}
+
+	if actual, _ := regexSplit(`\s*[,;]\s*`, "a, b;c"); !reflect.DeepEqual(actual, []string{"a", "b", "c"}) {
+		t.Errorf("regexSplit was incorrect, got: %q", actual)
+	}
+
+	captures, _ := regexCaptures(`^v(?P<version>[0-9.]+)(-(?P<pre>.+))?$`, "v1.2.3-rc1")
+	expected := map[string]string{"version": "1.2.3", "pre": "rc1"}
+	if !reflect.DeepEqual(captures, expected) {
+		t.Errorf("regexCaptures was incorrect, got: %v, want: %v.", captures, expected)
+	}
+	if captures, _ := regexCaptures(`^v(?P<version>\d+)$`, "main"); len(captures) != 0 {
+		t.Errorf("regexCaptures without match expected empty map, got: %v", captures)
+	}
+}
+
+func TestRegexInvalidPattern(t *testing.T) {
+	fns := map[string]func() error{
+		"regexMatch":      func() error { _, err := regexMatch(`(`, "("); return err },
+		"regexFind":       func() error { _, err := regexFind(`(`, "("); return err },
+		"regexFindAll":    func() error { _, err := regexFindAll(`(`, "("); return err },
+		"regexReplaceAll": func() error { _, err := regexReplaceAll(`(`, "-", "("); return err },
+		"regexSplit":      func() error { _, err := regexSplit(`(`, "("); return err },
+		"regexCaptures":   func() error { _, err := regexCaptures(`(`, "("); return err },
+	}
+	for name, fn := range fns {
+		if err := fn(); err == nil {
+			t.Errorf("%s with invalid pattern expected error, got nil", name)
+		}
+	}
+
+	_, err := executeTemplate("test", `{{ "a" | regexReplaceAll "(" "-" }}`, templateFuncs("test"), nil)
+	if err == nil {
+		t.Errorf("template with invalid pattern expected error, got nil")
+	}
+}
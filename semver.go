@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// parseSemver parses version like "v1.2.3-rc1".
+// The result exposes .Major, .Minor, .Patch, .Prerelease and .Metadata.
+// Usage: {{ $v := semver .version }}v{{ $v.Major }}.{{ $v.Minor }}.
+func parseSemver(in string) (*semver.Version, error) {
+	v, err := semver.NewVersion(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version %q: %w", in, err)
+	}
+	return v, nil
+}
+
+// semverCompare reports whether version satisfies constraint.
+// Usage: {{ if semverCompare ">=1.2 <2" .version }}.
+func semverCompare(constraint, in string) (bool, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse constraint %q: %w", constraint, err)
+	}
+	v, err := parseSemver(in)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(v), nil
+}
+
+// semverBump increments "major", "minor" or "patch" part of version,
+// keeping the "v" prefix if present.
+// Usage: {{ .version | semverBump "minor" }}.
+func semverBump(part, in string) (string, error) {
+	v, err := parseSemver(in)
+	if err != nil {
+		return "", err
+	}
+
+	var next semver.Version
+	switch part {
+	case "major":
+		next = v.IncMajor()
+	case "minor":
+		next = v.IncMinor()
+	case "patch":
+		next = v.IncPatch()
+	default:
+		return "", fmt.Errorf("unsupported version part %q, expected major, minor or patch", part)
+	}
+	return next.Original(), nil
+}
+
+// semverSort sorts list of versions in ascending order.
+// Usage: {{ .tags | semverSort | last }}.
+func semverSort(in interface{}) ([]string, error) {
+	list, err := toStrings(in)
+	if err != nil {
+		return nil, fmt.Errorf("semverSort: %w", err)
+	}
+
+	versions := make([]*semver.Version, 0, len(list))
+	for _, s := range list {
+		v, err := parseSemver(s)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	sort.Stable(semver.Collection(versions))
+
+	result := make([]string, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, v.Original())
+	}
+	return result, nil
+}
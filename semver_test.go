@@ -0,0 +1,88 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSemver(t *testing.T) {
+	v, err := parseSemver("v1.2.3-rc1+build.5")
+	if err != nil {
+		t.Fatalf("parseSemver returned an error: %v", err)
+	}
+	if v.Major() != 1 || v.Minor() != 2 || v.Patch() != 3 || v.Prerelease() != "rc1" || v.Metadata() != "build.5" {
+		t.Errorf("parseSemver was incorrect, got: %v", v)
+	}
+
+	if _, err := parseSemver("latest"); err == nil {
+		t.Errorf("parseSemver(%q) succeeded, but was expected to fail", "latest")
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		constraint, version string
+		expected            bool
+	}{
+		{">=1.2 <2", "v1.2.0", true},
+		{">=1.2 <2", "1.10.3", true},
+		{">=1.2 <2", "2.0.0", false},
+		{"~1.2", "1.2.9", true},
+		{"^1", "v2.0.0", false},
+	}
+
+	for _, tt := range tests {
+		actual, err := semverCompare(tt.constraint, tt.version)
+		if err != nil {
+			t.Errorf("semverCompare(%q, %q) returned an error: %v", tt.constraint, tt.version, err)
+		}
+		if actual != tt.expected {
+			t.Errorf("semverCompare(%q, %q) was incorrect, got: %v, want: %v.", tt.constraint, tt.version, actual, tt.expected)
+		}
+	}
+
+	if _, err := semverCompare("!!", "1.0.0"); err == nil {
+		t.Errorf("semverCompare with invalid constraint succeeded, but was expected to fail")
+	}
+}
+
+func TestSemverBump(t *testing.T) {
+	tests := []struct {
+		part, version string
+		expected      string
+	}{
+		{"major", "v1.2.3", "v2.0.0"},
+		{"minor", "v1.2.3", "v1.3.0"},
+		{"patch", "1.2.3", "1.2.4"},
+		{"patch", "1.2.3-rc1", "1.2.3"},
+	}
+
+	for _, tt := range tests {
+		actual, err := semverBump(tt.part, tt.version)
+		if err != nil {
+			t.Errorf("semverBump(%q, %q) returned an error: %v", tt.part, tt.version, err)
+		}
+		if actual != tt.expected {
+			t.Errorf("semverBump(%q, %q) was incorrect, got: %q, want: %q.", tt.part, tt.version, actual, tt.expected)
+		}
+	}
+
+	if _, err := semverBump("build", "1.0.0"); err == nil {
+		t.Errorf("semverBump with unsupported part succeeded, but was expected to fail")
+	}
+}
+
+func TestSemverSort(t *testing.T) {
+	actual, err := semverSort([]interface{}{"v1.10.0", "v1.2.0", "v1.2.0-rc1", "v0.9.1"})
+	if err != nil {
+		t.Fatalf("semverSort returned an error: %v", err)
+	}
+	expected := []string{"v0.9.1", "v1.2.0-rc1", "v1.2.0", "v1.10.0"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("semverSort was incorrect, got: %v, want: %v.", actual, expected)
+	}
+
+	if _, err := semverSort([]string{"v1", "latest"}); err == nil {
+		t.Errorf("semverSort with invalid version succeeded, but was expected to fail")
+	}
+}
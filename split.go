@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// sourceMarker starts a line routing the following content to another file,
+// the same way Helm marks templates in rendered manifests.
+const sourceMarker = "# Source: "
+
+// fileMarker returns marker line for the split input:
+// everything after it, up to the next marker, is written to the file.
+// Usage: {{ range .services }}{{ file (printf "%s.yml" .name) }}...{{ end }}.
+func fileMarker(path string) string {
+	return "\n" + sourceMarker + path + "\n"
+}
+
+// section is a part of rendered output routed to its own file.
+type section struct {
+	path    string
+	content string
+}
+
+// splitOutput splits output by "# Source: path" marker lines.
+// Content before the first marker is returned as is.
+// Blank lines and YAML document separators ("---") around sections are trimmed.
+func splitOutput(output string) (string, []section, error) {
+	lines := strings.SplitAfter(output, "\n")
+
+	var rest strings.Builder
+	var sections []section
+	seen := map[string]bool{}
+	var current *strings.Builder
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		sections[len(sections)-1].content = trimSection(current.String())
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimRight(line, "\r\n")
+		if !strings.HasPrefix(trimmed, sourceMarker) {
+			if current == nil {
+				rest.WriteString(line)
+			} else {
+				current.WriteString(line)
+			}
+			continue
+		}
+
+		path := strings.TrimSpace(strings.TrimPrefix(trimmed, sourceMarker))
+		if path == "" {
+			return "", nil, fmt.Errorf("file path is empty")
+		}
+		if seen[path] {
+			return "", nil, fmt.Errorf("file %q is used more than once", path)
+		}
+		seen[path] = true
+
+		flush()
+		current = &strings.Builder{}
+		sections = append(sections, section{path: path})
+	}
+	flush()
+
+	if len(sections) == 0 {
+		return output, nil, nil
+	}
+	return trimSection(rest.String()), sections, nil
+}
+
+// trimSection removes blank lines and YAML document separators
+// from both ends of section, keeping single trailing new line.
+func trimSection(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	isEdge := func(line string) bool {
+		line = strings.TrimSpace(line)
+		return line == "" || line == "---"
+	}
+	for len(lines) > 0 && isEdge(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && isEdge(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// split routes sections of rendered output to their files,
+// relative to the result path directory.
+// Content outside of sections stays in the result file, if it's not blank.
+func (r rendered) split() ([]rendered, error) {
+	rest, sections, err := splitOutput(r.output)
+	if err != nil {
+		return nil, err
+	}
+	if sections == nil {
+		return []rendered{r}, nil
+	}
+	if r.path == "" {
+		return nil, fmt.Errorf("result_path is required to split output, files are written relative to its directory")
+	}
+
+	var results []rendered
+	if rest != "" {
+		results = append(results, rendered{output: rest, path: r.path})
+	}
+
+	dir := filepath.Dir(r.path)
+	for _, s := range sections {
+		path := filepath.FromSlash(s.path)
+		if !filepath.IsLocal(path) {
+			return nil, fmt.Errorf("file %q must be relative to result directory and stay inside it", s.path)
+		}
+		results = append(results, rendered{output: s.content, path: filepath.Join(dir, path)})
+	}
+	return results, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitOutput(t *testing.T) {
+	tests := []struct {
+		in               string
+		expectedRest     string
+		expectedSections []section
+	}{
+		{
+			"---\n# Source: web.yml\nkind: Service\n---\n# Source: worker.yml\nkind: Deployment\n",
+			"",
+			[]section{
+				{"web.yml", "kind: Service\n"},
+				{"worker.yml", "kind: Deployment\n"},
+			},
+		},
+		{
+			"index\n" + fileMarker("a/b.txt") + "\nhello\n\n",
+			"index\n",
+			[]section{{"a/b.txt", "hello\n"}},
+		},
+		{
+			"# Source: empty.txt",
+			"",
+			[]section{{"empty.txt", ""}},
+		},
+		{
+			"no markers\n",
+			"no markers\n",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		rest, sections, err := splitOutput(tt.in)
+		if err != nil {
+			t.Errorf("splitOutput(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if rest != tt.expectedRest {
+			t.Errorf("splitOutput(%q) rest was incorrect, got: %q, want: %q.", tt.in, rest, tt.expectedRest)
+		}
+		if !reflect.DeepEqual(sections, tt.expectedSections) {
+			t.Errorf("splitOutput(%q) sections were incorrect, got: %+v, want: %+v.", tt.in, sections, tt.expectedSections)
+		}
+	}
+}
+
+func TestRenderedSplit(t *testing.T) {
+	r := rendered{
+		output: "# Source: web.yml\nkind: Service\n",
+		path:   "out/all.yml",
+	}
+	results, err := r.split()
+	if err != nil {
+		t.Fatalf("split() returned error: %v", err)
+	}
+	expected := []rendered{{"kind: Service\n", filepath.Join("out", "web.yml")}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("split() was incorrect, got: %+v, want: %+v.", results, expected)
+	}
+
+	for _, in := range []string{
+		"# Source: ../web.yml\n",
+		"# Source: /etc/passwd\n",
+		"# Source: a.yml\n# Source: a.yml\n",
+		"# Source: \n",
+	} {
+		if _, err := (rendered{output: in, path: "out/all.yml"}).split(); err == nil {
+			t.Errorf("split() of %q expected error, got nil", in)
+		}
+	}
+
+	if _, err := (rendered{output: "# Source: web.yml\n"}).split(); err == nil {
+		t.Errorf("split() without result path expected error, got nil")
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// textArgs splits template function arguments into an optional locale
+// and the string to transform (the last argument, so it can be piped).
+func textArgs(name string, args []string) (language.Tag, string, error) {
+	switch len(args) {
+	case 1:
+		return defaultLocale, args[0], nil
+	case 2:
+		tag, err := language.Parse(args[0])
+		if err != nil {
+			return defaultLocale, args[1], fmt.Errorf("%s: failed to parse locale %q: %w", name, args[0], err)
+		}
+		return tag, args[1], nil
+	default:
+		return defaultLocale, "", fmt.Errorf("%s: expected 1 or 2 arguments, got %d", name, len(args))
+	}
+}
+
+// title converts the first letter of each word to title case.
+// Usage: {{ .name | title }} or {{ .name | title "tr" }}.
+func title(args ...string) string {
+	tag, in, err := textArgs("title", args)
+	if err != nil {
+		log.Print(err)
+		return in
+	}
+	return cases.Title(tag).String(in)
+}
+
+// upper converts string to upper case using locale rules (e.g. "i" to "İ" in Turkish).
+func upper(args ...string) string {
+	tag, in, err := textArgs("upper", args)
+	if err != nil {
+		log.Print(err)
+		return in
+	}
+	return cases.Upper(tag).String(in)
+}
+
+// lower converts string to lower case using locale rules (e.g. "I" to "ı" in Turkish).
+func lower(args ...string) string {
+	tag, in, err := textArgs("lower", args)
+	if err != nil {
+		log.Print(err)
+		return in
+	}
+	return cases.Lower(tag).String(in)
+}
+
+// camelCase joins words as "fooBarBaz".
+func camelCase(args ...string) string {
+	tag, in, err := textArgs("camelcase", args)
+	if err != nil {
+		log.Print(err)
+		return in
+	}
+	lowerCaser, titleCaser := cases.Lower(tag), cases.Title(tag)
+	words := splitWords(in)
+	for i, w := range words {
+		if i == 0 {
+			words[i] = lowerCaser.String(w)
+			continue
+		}
+		words[i] = titleCaser.String(w)
+	}
+	return strings.Join(words, "")
+}
+
+// snakeCase joins words as "foo_bar_baz".
+func snakeCase(args ...string) string {
+	return joinWords("snakecase", "_", args)
+}
+
+// kebabCase joins words as "foo-bar-baz".
+func kebabCase(args ...string) string {
+	return joinWords("kebabcase", "-", args)
+}
+
+func joinWords(name, sep string, args []string) string {
+	tag, in, err := textArgs(name, args)
+	if err != nil {
+		log.Print(err)
+		return in
+	}
+	return cases.Lower(tag).String(strings.Join(splitWords(in), sep))
+}
+
+// splitWords splits string into words on any non-alphanumeric characters
+// and on case changes, so "HTTPServer_name" becomes ["HTTP", "Server", "name"].
+func splitWords(in string) []string {
+	var words []string
+	var word []rune
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+
+	rs := []rune(in)
+	for i, r := range rs {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextIsLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+
+	return words
+}
+
+// normalize converts string to one of the Unicode normalization forms:
+// NFC, NFD, NFKC or NFKD.
+// Usage: {{ .name | normalize "NFC" }}.
+func normalize(form, in string) string {
+	switch strings.ToUpper(form) {
+	case "NFC":
+		return norm.NFC.String(in)
+	case "NFD":
+		return norm.NFD.String(in)
+	case "NFKC":
+		return norm.NFKC.String(in)
+	case "NFKD":
+		return norm.NFKD.String(in)
+	default:
+		log.Printf("unsupported normalization form %q", form)
+		return in
+	}
+}
+
+// transliterations covers letters that do not decompose into ASCII
+// with a combining mark (e.g. "ß" or Cyrillic letters).
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'ø': "o", 'Ø': "O", 'œ': "oe", 'Œ': "OE",
+	'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'þ': "th", 'Þ': "TH", 'ð': "d", 'Ð': "D",
+	'ı': "i",
+
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+}
+
+// slugify converts string to a lower case ASCII slug,
+// transliterating accented and Cyrillic letters, e.g. "Crème Brûlée" to "creme-brulee".
+func slugify(args ...string) string {
+	tag, in, err := textArgs("slugify", args)
+	if err != nil {
+		log.Print(err)
+		return in
+	}
+
+	t := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	s, _, err := transform.String(t, cases.Lower(tag).String(in))
+	if err != nil {
+		log.Printf("failed to transliterate %q: %v", in, err)
+		s = in
+	}
+
+	var b strings.Builder
+	dash := false
+	for _, r := range s {
+		if tr, ok := transliterations[unicode.ToLower(r)]; ok {
+			if tr == "" {
+				continue
+			}
+			b.WriteString(tr)
+			dash = false
+			continue
+		}
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(unicode.ToLower(r))
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// truncate shortens string to at most n user-perceived characters,
+// never splitting combining marks, emoji sequences or flags.
+// Usage: {{ .description | truncate 80 }}.
+func truncate(n int, in string) string {
+	if n < 0 {
+		return in
+	}
+	rs := []rune(in)
+	for i := 0; i < len(rs); n-- {
+		if n == 0 {
+			return string(rs[:i])
+		}
+		i = nextGrapheme(rs, i)
+	}
+	return in
+}
+
+// nextGrapheme returns the index of the rune following the grapheme
+// cluster that starts at i. This is a simplified version of the
+// Unicode segmentation rules covering combining marks, variation
+// selectors, emoji modifiers, ZWJ sequences and regional indicator pairs.
+func nextGrapheme(rs []rune, i int) int {
+	start := rs[i]
+	i++
+	if isRegionalIndicator(start) && i < len(rs) && isRegionalIndicator(rs[i]) {
+		return i + 1
+	}
+	if start == '\r' && i < len(rs) && rs[i] == '\n' {
+		return i + 1
+	}
+	for i < len(rs) {
+		r := rs[i]
+		switch {
+		case unicode.Is(unicode.M, r),
+			r >= 0xFE00 && r <= 0xFE0F,   // variation selectors
+			r >= 0x1F3FB && r <= 0x1F3FF: // emoji modifiers
+			i++
+		case r == 0x200D: // zero width joiner
+			i++
+			if i < len(rs) {
+				i++
+			}
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// indent prefixes every line of string with n spaces.
+// Usage: {{ readFile "nginx.conf" | indent 4 }}.
+func indent(n int, in string) string {
+	pad := strings.Repeat(" ", n)
+	return pad + strings.ReplaceAll(in, "\n", "\n"+pad)
+}
+
+// nindent is like indent, but starts with a new line.
+func nindent(n int, in string) string {
+	return "\n" + indent(n, in)
+}
@@ -0,0 +1,79 @@
+package main
+
+import "testing"
+
+func TestTextCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(...string) string
+		args     []string
+		expected string
+	}{
+		{"title", title, []string{"hello world"}, "Hello World"},
+		{"title", title, []string{"nl", "ijssel meer"}, "IJssel Meer"},
+		{"upper", upper, []string{"tr", "istanbul"}, "İSTANBUL"},
+		{"lower", lower, []string{"tr", "ISPARTA"}, "ısparta"},
+		{"camelcase", camelCase, []string{"foo_bar-baz"}, "fooBarBaz"},
+		{"camelcase", camelCase, []string{"HTTPServer name"}, "httpServerName"},
+		{"snakecase", snakeCase, []string{"fooBarBaz"}, "foo_bar_baz"},
+		{"snakecase", snakeCase, []string{"HTTPServer2Name"}, "http_server2_name"},
+		{"kebabcase", kebabCase, []string{"My Service.Name"}, "my-service-name"},
+		{"kebabcase", kebabCase, []string{"tr", "IŞIK Service"}, "ışık-service"},
+		{"slugify", slugify, []string{"Crème Brûlée!"}, "creme-brulee"},
+		{"slugify", slugify, []string{"  Straße & Ørsted  "}, "strasse-orsted"},
+		{"slugify", slugify, []string{"Привет, мир"}, "privet-mir"},
+		{"slugify", slugify, []string{"tr", "IŞIK"}, "isik"},
+	}
+
+	for _, tt := range tests {
+		actual := tt.fn(tt.args...)
+		if actual != tt.expected {
+			t.Errorf("%s(%q) was incorrect, got: %q, want: %q.", tt.name, tt.args, actual, tt.expected)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		form     string
+		in       string
+		expected string
+	}{
+		{"NFC", "e\u0301", "\u00e9"},
+		{"NFD", "\u00e9", "e\u0301"},
+		{"NFKD", "ﬁ", "fi"},
+		{"nfkc", "①", "1"},
+		{"unknown", "é", "é"},
+	}
+
+	for _, tt := range tests {
+		actual := normalize(tt.form, tt.in)
+		if actual != tt.expected {
+			t.Errorf("normalize(%q, %q) was incorrect, got: %q, want: %q.", tt.form, tt.in, actual, tt.expected)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		n        int
+		in       string
+		expected string
+	}{
+		{5, "hello world", "hello"},
+		{20, "hello", "hello"},
+		{0, "hello", ""},
+		{-1, "hello", "hello"},
+		{2, "e\u0301e\u0301e\u0301", "e\u0301e\u0301"},
+		{1, "\U0001F1FA\U0001F1F8\U0001F1E9\U0001F1EA", "\U0001F1FA\U0001F1F8"},
+		{1, "\U0001F468‍\U0001F469‍\U0001F467!", "\U0001F468‍\U0001F469‍\U0001F467"},
+		{2, "\U0001F44D\U0001F3FDok", "\U0001F44D\U0001F3FDo"},
+	}
+
+	for _, tt := range tests {
+		actual := truncate(tt.n, tt.in)
+		if actual != tt.expected {
+			t.Errorf("truncate(%d, %q) was incorrect, got: %q, want: %q.", tt.n, tt.in, actual, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// maxTplDepth limits nesting of tpl calls, so a value that renders itself
+// (e.g. `url: "{{ tpl .url . }}"`) fails instead of running forever.
+const maxTplDepth = 10
+
+// tplRenderer renders strings as templates with the same functions
+// as the template they are called from.
+type tplRenderer struct {
+	funcs template.FuncMap
+	depth int
+}
+
+// templateFuncs returns funcMap extended with functions bound to the template file.
+func templateFuncs(templateFilePath string) template.FuncMap {
+	funcs := template.FuncMap{}
+	for name, fn := range funcMap {
+		funcs[name] = fn
+	}
+	for name, fn := range newFileReader(templateFilePath).funcs() {
+		funcs[name] = fn
+	}
+
+	r := &tplRenderer{funcs: funcs}
+	funcs["tpl"] = r.tpl
+	return funcs
+}
+
+// tpl renders string as a template with the given data.
+// Usage: {{ tpl .url . }}.
+func (r *tplRenderer) tpl(text string, data interface{}) (string, error) {
+	if r.depth >= maxTplDepth {
+		return "", fmt.Errorf("tpl: maximum nesting depth of %d exceeded", maxTplDepth)
+	}
+	r.depth++
+	defer func() { r.depth-- }()
+
+	result, err := executeTemplate("tpl", text, r.funcs, data)
+	if err != nil {
+		return "", fmt.Errorf("tpl: %w", err)
+	}
+	return result, nil
+}
+
+// executeTemplate parses text as a template and executes it with data.
+func executeTemplate(name, text string, funcs template.FuncMap, data interface{}) (string, error) {
+	tmpl, err := template.
+		New(name).
+		Option("missingkey=error").
+		Funcs(funcs).
+		Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var result bytes.Buffer
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
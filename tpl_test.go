@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTpl(t *testing.T) {
+	tpl := templateFuncs("./testdata/template.txt")["tpl"].(func(string, interface{}) (string, error))
+
+	tests := []struct {
+		text     string
+		data     interface{}
+		expected string
+	}{
+		{"https://{{ .host }}/api", vars{"host": "example.com"}, "https://example.com/api"},
+		{"{{ .name | upper }}", vars{"name": "app"}, "APP"},
+		{`{{ tpl .inner . }}`, vars{"inner": "{{ .host }}", "host": "nested"}, "nested"},
+		{"plain text", nil, "plain text"},
+	}
+
+	for _, tt := range tests {
+		actual, err := tpl(tt.text, tt.data)
+		if err != nil {
+			t.Errorf("tpl(%q) returned an error: %v", tt.text, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf("tpl(%q) was incorrect, got: %q, want: %q.", tt.text, actual, tt.expected)
+		}
+	}
+
+	if _, err := tpl("{{ .missing }}", vars{}); err == nil {
+		t.Errorf("tpl with missing key succeeded, but was expected to fail")
+	}
+
+	_, err := tpl("{{ tpl .url . }}", vars{"url": "{{ tpl .url . }}"})
+	if err == nil || !strings.Contains(err.Error(), "maximum nesting depth") {
+		t.Errorf("tpl with self-reference expected depth error, got: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// writeFile writes content to a temporary file next to path and renames it,
+// so readers never see a half-written file. Parent directories are created.
+// If keepMode is set and the file exists, its mode is kept instead of mode.
+// Symlinks are followed, so the link itself is not replaced;
+// target of a dangling symlink is created.
+// Owner and group of the replaced file are not kept, it's owned by the current user.
+// Existing file with the same content is not rewritten, so its modification time
+// stays the same; only its mode is updated if needed.
+// It reports whether the file content or mode changed.
+func writeFile(path string, content []byte, mode os.FileMode, keepMode bool) (bool, error) {
+	path, err := followSymlinks(path)
+	if err != nil {
+		return false, err
+	}
+
+	fi, err := os.Stat(path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if exists && keepMode {
+		mode = fi.Mode().Perm()
+	}
+
+	if exists {
+		current, err := os.ReadFile(path)
+		if err != nil {
+			return false, err
+		}
+		if bytes.Equal(current, content) {
+			if fi.Mode().Perm() == mode {
+				return false, nil
+			}
+			return true, os.Chmod(path, mode)
+		}
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return false, fmt.Errorf("failed to create directory %q: %w", dir, err)
+	}
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return false, err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // no-op after successful rename
+
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return false, err
+	}
+	if err := f.Close(); err != nil {
+		return false, err
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		return false, err
+	}
+	return true, os.Rename(tmp, path)
+}
+
+// maxSymlinks limits symlink chain length, as loops never end.
+const maxSymlinks = 255
+
+// followSymlinks returns path of the file that path points to.
+// Unlike filepath.EvalSymlinks, the file may not exist yet,
+// e.g. when path is a dangling symlink.
+func followSymlinks(path string) (string, error) {
+	for i := 0; i < maxSymlinks; i++ {
+		fi, err := os.Lstat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return path, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			return path, nil
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	return "", fmt.Errorf("too many levels of symbolic links in %q", path)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a", "b", "result.sh")
+
+	if _, err := writeFile(path, []byte("#!/bin/sh\n"), 0o755, true); err != nil {
+		t.Fatalf("writeFile() returned error: %v", err)
+	}
+	assertFile(t, path, "#!/bin/sh\n", 0o755)
+
+	// existing mode is preserved
+	if err := os.Chmod(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeFile(path, []byte("echo\n"), 0o644, true); err != nil {
+		t.Fatalf("writeFile() returned error: %v", err)
+	}
+	assertFile(t, path, "echo\n", 0o700)
+
+	// or replaced
+	if _, err := writeFile(path, []byte("echo\n"), 0o600, false); err != nil {
+		t.Fatalf("writeFile() returned error: %v", err)
+	}
+	assertFile(t, path, "echo\n", 0o600)
+
+	// symlinks are followed
+	link := filepath.Join(dir, "link.sh")
+	if err := os.Symlink(path, link); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeFile(link, []byte("exit\n"), 0o600, false); err != nil {
+		t.Fatalf("writeFile() returned error: %v", err)
+	}
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("writeFile() replaced symlink %q", link)
+	}
+	assertFile(t, path, "exit\n", 0o600)
+
+	// target of dangling symlink is created
+	dangling := filepath.Join(dir, "dangling.sh")
+	if err := os.Symlink(filepath.Join("a", "b", "new.sh"), dangling); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeFile(dangling, []byte("new\n"), 0o644, false); err != nil {
+		t.Fatalf("writeFile() returned error: %v", err)
+	}
+	if fi, err := os.Lstat(dangling); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("writeFile() replaced symlink %q", dangling)
+	}
+	assertFile(t, filepath.Join(dir, "a", "b", "new.sh"), "new\n", 0o644)
+
+	// symlink loops are reported
+	loop := filepath.Join(dir, "loop.sh")
+	if err := os.Symlink("loop.sh", loop); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeFile(loop, []byte("x\n"), 0o644, false); err == nil {
+		t.Errorf("writeFile() of symlink loop expected error, got nil")
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("writeFile() left temporary files: %v", entries)
+	}
+}
+
+func TestWriteFileUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.yml")
+
+	tests := []struct {
+		content  string
+		mode     os.FileMode
+		expected bool
+	}{
+		{"a: 1\n", 0o644, true},  // created
+		{"a: 1\n", 0o644, false}, // same content
+		{"a: 2\n", 0o644, true},  // new content
+		{"a: 2\n", 0o600, true},  // new mode
+	}
+	for i, tt := range tests {
+		changed, err := writeFile(path, []byte(tt.content), tt.mode, false)
+		if err != nil {
+			t.Fatalf("writeFile() #%d returned error: %v", i, err)
+		}
+		if changed != tt.expected {
+			t.Errorf("writeFile() #%d changed was incorrect, got: %v, want: %v.", i, changed, tt.expected)
+		}
+		assertFile(t, path, tt.content, tt.mode)
+	}
+
+	// modification time is kept for unchanged file
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeFile(path, []byte("a: 2\n"), 0o600, false); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.ModTime().Equal(old) {
+		t.Errorf("writeFile() updated modification time of unchanged file, got: %v, want: %v.", fi.ModTime(), old)
+	}
+}
+
+func assertFile(t *testing.T, path, expected string, expectedMode os.FileMode) {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != expected {
+		t.Errorf("file %q content was incorrect, got: %q, want: %q.", path, b, expected)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != expectedMode {
+		t.Errorf("file %q mode was incorrect, got: %v, want: %v.", path, fi.Mode().Perm(), expectedMode)
+	}
+}